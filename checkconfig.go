@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"clockifill/clockify"
+)
+
+// runCheckConfig lints the config file before a fill relies on it: every
+// project and task must exist in the workspace and every project's hours
+// must parse, be in order and not overlap another project's. It reports all
+// problems and changes nothing. Any problem is returned as an error.
+func runCheckConfig(args []string) error {
+	fs := flag.NewFlagSet("check-config", flag.ExitOnError)
+	configPath := fs.String("config", "", "config file to check (default: ~/.clockifill.yaml)")
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	path := *configPath
+	if path == "" {
+		if path = defaultConfigPath(); path == "" {
+			return fmt.Errorf("cannot find your home directory; pass --config")
+		}
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		return fmt.Errorf("error initializing Clockify API: %v", err)
+	}
+	projects, err := api.GetProjects()
+	if err != nil {
+		return fmt.Errorf("error getting projects: %v", err)
+	}
+
+	names := make([]string, 0, len(cfg.Projects))
+	for name := range cfg.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	var withHours []clockify.Project
+	for _, name := range names {
+		projectCfg := cfg.Projects[name]
+		project, found := clockify.Project{}, false
+		for _, candidate := range projects {
+			if strings.EqualFold(candidate.Name, name) {
+				project, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			problem := fmt.Sprintf("project %q: not found in the workspace", name)
+			if match, err := clockify.FindProject(projects, name); err == nil {
+				problem += fmt.Sprintf(" (did you mean %q?)", match.Name)
+			}
+			problems = append(problems, problem)
+		}
+
+		if projectCfg.Task != "" && found {
+			tasks, err := api.GetTasks(project.ID)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("project %q: cannot get tasks: %v", name, err))
+			} else if _, err := clockify.FindTaskByName(clockify.ActiveTasks(tasks), projectCfg.Task, project); err != nil {
+				if _, doneErr := clockify.FindTaskByName(tasks, projectCfg.Task, project); doneErr == nil {
+					err = fmt.Errorf("task %q is done; fill needs --include-done-tasks to log against it", projectCfg.Task)
+				}
+				problems = append(problems, fmt.Sprintf("project %q: %v", name, err))
+			}
+		}
+
+		start, end, err := projectCfg.hours()
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("project %q: %v", name, err))
+		case (projectCfg.Start == "") != (projectCfg.End == ""):
+			problems = append(problems, fmt.Sprintf("project %q: start and end must be set together", name))
+		case projectCfg.Start != "" && end <= start:
+			problems = append(problems, fmt.Sprintf("project %q: end %s is not after start %s", name, projectCfg.End, projectCfg.Start))
+		case projectCfg.Start != "":
+			// Named as in the config file, so overlaps are reported that way
+			withHours = append(withHours, clockify.Project{Name: name})
+		}
+
+		if projectCfg.Meetings != "" {
+			if _, err := regexp.Compile(projectCfg.Meetings); err != nil {
+				problems = append(problems, fmt.Sprintf("project %q: invalid meetings pattern: %v", name, err))
+			}
+		}
+	}
+	if err := checkProjectHours(cfg, withHours); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			problems = append(problems, line)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in %s:\n  %s", len(problems), path, strings.Join(problems, "\n  "))
+	}
+	fmt.Printf("%s is OK: %d projects checked\n", path, len(names))
+	return nil
+}
@@ -0,0 +1,788 @@
+// Package clockify implements ClockiFill's core: a small client for the
+// Clockify API and the logic that fills working days with time entries.
+package clockify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// baseURL is where API requests are sent; tests point it at a local server.
+var baseURL = "https://api.clockify.me/api/v1"
+
+// injectFault, when set, is consulted before each request is sent and may
+// return a synthetic response or error in its place. Outside tests it is
+// only ever set by builds with the simulate_errors tag (see faults.go).
+var injectFault func(req *http.Request) (*http.Response, error)
+
+// Config holds the settings for connecting to Clockify.
+type Config struct {
+	APIKey string
+	// WorkspaceID pins the workspace to use; it must be one of the user's
+	// workspaces. Empty means the first workspace.
+	WorkspaceID string
+	// ReadTimeout and WriteTimeout bound requests that read data and
+	// requests that create or change it. Zero means no timeout.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// MaxRetries is how many times a request that failed with a rate limit,
+	// a server error or a network error is retried. Zero disables retries.
+	MaxRetries int
+}
+
+// API is a Clockify client bound to the user's workspace.
+type API struct {
+	apiKey       string
+	workspaceID  string
+	userID       string
+	client       *http.Client
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	maxRetries   int
+	statsMu      sync.Mutex
+	stats        RetryStats
+
+	settingsOnce sync.Once
+	settings     workspaceSettings
+	settingsErr  error
+
+	// customFields are set on every created entry; see SetSourceField.
+	customFields []CustomFieldValue
+
+	// clockSkew is how far the local clock is ahead of Clockify's, from
+	// the Date header of the user lookup; zero if the header was missing.
+	clockSkew time.Duration
+}
+
+// NewAPI connects to Clockify and looks up the user and workspace the API
+// key belongs to.
+func NewAPI(config Config) (*API, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+
+	api := &API{
+		apiKey:       config.APIKey,
+		client:       &http.Client{},
+		readTimeout:  config.ReadTimeout,
+		writeTimeout: config.WriteTimeout,
+		maxRetries:   config.MaxRetries,
+	}
+
+	var err error
+	if api.workspaceID, err = api.getWorkspaceID(config.WorkspaceID); err != nil {
+		return nil, err
+	}
+
+	if api.userID, err = api.getUserID(); err != nil {
+		return nil, err
+	}
+
+	return api, nil
+}
+
+// WorkspaceID returns the ID of the workspace the API works in.
+func (api *API) WorkspaceID() string {
+	return api.workspaceID
+}
+
+// UserID returns the ID of the user the API key belongs to.
+func (api *API) UserID() string {
+	return api.userID
+}
+
+// ClockSkew returns how far the local clock is ahead of Clockify's (negative
+// if behind), to the second, as measured when connecting.
+func (api *API) ClockSkew() time.Duration {
+	return api.clockSkew
+}
+
+// RetryStats returns how many requests have been retried so far.
+func (api *API) RetryStats() RetryStats {
+	api.statsMu.Lock()
+	defer api.statsMu.Unlock()
+	return api.stats
+}
+
+// RetryStats counts the retries performed by an API, to show how flaky a run
+// was.
+type RetryStats struct {
+	Retries        int
+	RateLimitWaits int
+	Waited         time.Duration
+}
+
+func (api *API) recordRetry(wait time.Duration, rateLimited bool) {
+	api.statsMu.Lock()
+	defer api.statsMu.Unlock()
+	api.stats.Retries++
+	if rateLimited {
+		api.stats.RateLimitWaits++
+	}
+	api.stats.Waited += wait
+}
+
+func (s RetryStats) String() string {
+	return fmt.Sprintf("Retries: %d (rate-limit waits: %d, total %.1fs)", s.Retries, s.RateLimitWaits, s.Waited.Seconds())
+}
+
+func (api *API) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
+	return api.makeRequestContext(context.Background(), method, endpoint, body)
+}
+
+// makeRequestContext is makeRequest with the requests and the waits between
+// them cut short once ctx is done.
+func (api *API) makeRequestContext(ctx context.Context, method, endpoint string, body interface{}) (*http.Response, error) {
+	var jsonData []byte
+	if body != nil {
+		var err error
+		if jsonData, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := api.sendRequest(ctx, method, endpoint, jsonData)
+		wait, retry := retryDelay(method, resp, err, attempt)
+		if !retry || attempt >= api.maxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		api.recordRetry(wait, resp != nil && resp.StatusCode == http.StatusTooManyRequests)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryBackoff is the wait before the first retry of a failed request; it
+// doubles with each further attempt. Tests set it to zero.
+var retryBackoff = time.Second
+
+// retryDelay reports whether a failed attempt should be retried and how long
+// to wait first. Rate-limited requests were never processed, so they are
+// retried whatever the method; server errors and network failures are only
+// retried for requests that are safe to repeat, so a retried POST can't
+// create an entry twice.
+func retryDelay(method string, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := retryBackoff << attempt
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		return backoff, true
+	}
+
+	idempotent := method == "GET" || method == "DELETE"
+	if err != nil || resp.StatusCode >= 500 {
+		return backoff, idempotent
+	}
+	return 0, false
+}
+
+func (api *API) sendRequest(parent context.Context, method, endpoint string, jsonData []byte) (*http.Response, error) {
+	var bodyReader io.Reader
+	if jsonData != nil {
+		bodyReader = bytes.NewReader(jsonData)
+	}
+
+	// Reads and writes get separate timeouts, covering the whole exchange
+	// including reading the response body
+	timeout := api.writeTimeout
+	if method == "GET" {
+		timeout = api.readTimeout
+	}
+	ctx, cancel := context.WithCancel(parent)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	}
+
+	// Endpoints on other hosts, such as time off, are passed as full URLs
+	url := baseURL + endpoint
+	if strings.HasPrefix(endpoint, "https://") {
+		url = endpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	req.Header.Set("X-Api-Key", api.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	if injectFault != nil {
+		if resp, err := injectFault(req); resp != nil || err != nil {
+			cancel()
+			return resp, err
+		}
+	}
+
+	resp, err := api.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelOnClose releases a request's context once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// getWorkspaceID returns wantID after checking the user is a member of that
+// workspace, or the first workspace if wantID is empty.
+func (api *API) getWorkspaceID(wantID string) (string, error) {
+	workspaces, err := api.GetWorkspaces()
+	if err != nil {
+		return "", err
+	}
+
+	if len(workspaces) == 0 {
+		return "", fmt.Errorf("no workspaces found")
+	}
+
+	if wantID != "" {
+		for _, workspace := range workspaces {
+			if workspace.ID == wantID {
+				return workspace.ID, nil
+			}
+		}
+		return "", fmt.Errorf("workspace %s not found among your workspaces", wantID)
+	}
+
+	return workspaces[0].ID, nil
+}
+
+// GetWorkspaces returns the workspaces the user belongs to.
+func (api *API) GetWorkspaces() ([]Workspace, error) {
+	resp, err := api.makeRequest("GET", "/workspaces", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var workspaces []Workspace
+	if err := decodeResponse(resp, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+func (api *API) getUserID() (string, error) {
+	resp, err := api.makeRequest("GET", "/user", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := decodeResponse(resp, &user); err != nil {
+		return "", err
+	}
+
+	if serverTime, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		api.clockSkew = time.Since(serverTime).Round(time.Second)
+	}
+
+	return user.ID, nil
+}
+
+// GetHourlyRate returns the rate that applies to entries on the project: the
+// project's own rate if set, otherwise the user's workspace rate. It returns
+// nil when neither is configured.
+func (api *API) GetHourlyRate(project Project) (*HourlyRate, error) {
+	if project.HourlyRate != nil && project.HourlyRate.Amount > 0 {
+		return project.HourlyRate, nil
+	}
+
+	resp, err := api.makeRequest("GET", "/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var user struct {
+		Memberships []Membership `json:"memberships"`
+	}
+	if err := decodeResponse(resp, &user); err != nil {
+		return nil, err
+	}
+
+	for _, membership := range user.Memberships {
+		if membership.MembershipType == "WORKSPACE" && membership.TargetID == api.workspaceID &&
+			membership.HourlyRate != nil && membership.HourlyRate.Amount > 0 {
+			return membership.HourlyRate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetWorkspaceUsers returns all members of the workspace.
+func (api *API) GetWorkspaceUsers() ([]WorkspaceUser, error) {
+	const pageSize = 500
+
+	var users []WorkspaceUser
+	for page := 1; ; page++ {
+		resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/users?page=%d&page-size=%d", api.workspaceID, page, pageSize), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var pageUsers []WorkspaceUser
+		err = decodeResponse(resp, &pageUsers)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, pageUsers...)
+		if len(pageUsers) < pageSize {
+			return users, nil
+		}
+	}
+}
+
+// ResolveUserID returns the ID of the workspace member with the given email.
+func (api *API) ResolveUserID(email string) (string, error) {
+	users, err := api.GetWorkspaceUsers()
+	if err != nil {
+		return "", err
+	}
+
+	var matches []WorkspaceUser
+	for _, user := range users {
+		if strings.EqualFold(user.Email, email) {
+			matches = append(matches, user)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no workspace member with email %s", email)
+	case 1:
+		return matches[0].ID, nil
+	default:
+		return "", fmt.Errorf("%d workspace members match email %s", len(matches), email)
+	}
+}
+
+func (api *API) GetProjects() ([]Project, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/projects", api.workspaceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var projects []Project
+	if err := decodeResponse(resp, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (api *API) GetTasks(projectID string) ([]Task, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/projects/%s/tasks", api.workspaceID, projectID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tasks []Task
+	if err := decodeResponse(resp, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// GetTimeEntries returns the user's entries between startTime and endTime,
+// limited to one project unless projectID is empty.
+func (api *API) GetTimeEntries(projectID string, startTime, endTime time.Time) ([]ExistingTimeEntry, error) {
+	const pageSize = 1000
+
+	var all []ExistingTimeEntry
+	for page := 1; ; page++ {
+		params := fmt.Sprintf("?start=%s&end=%s&page=%d&page-size=%d",
+			startTime.UTC().Format(time.RFC3339),
+			endTime.UTC().Format(time.RFC3339),
+			page, pageSize)
+		if projectID != "" {
+			params += "&project=" + projectID
+		}
+
+		endpoint := fmt.Sprintf("/workspaces/%s/user/%s/time-entries%s",
+			api.workspaceID, api.userID, params)
+
+		entries, err := api.getTimeEntriesPage(endpoint)
+		if errors.Is(err, errIncompleteBody) {
+			// Seeing no entries on a glitch would let a day be filled twice,
+			// so the page is asked for once more before giving up
+			entries, err = api.getTimeEntriesPage(endpoint)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, entries...)
+		if len(entries) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+// errIncompleteBody means a response body was empty or cut short. An empty
+// result is always sent as "[]", so no body at all is a glitch, not "no
+// entries".
+var errIncompleteBody = errors.New("incomplete response body")
+
+func (api *API) getTimeEntriesPage(endpoint string) ([]ExistingTimeEntry, error) {
+	resp, err := api.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errIncompleteBody, err)
+	}
+	if resp.StatusCode == http.StatusOK && (len(body) == 0 || (resp.ContentLength >= 0 && int64(len(body)) != resp.ContentLength)) {
+		return nil, fmt.Errorf("%w: got %d bytes (Content-Length %d)", errIncompleteBody, len(body), resp.ContentLength)
+	}
+
+	var entries []ExistingTimeEntry
+	if err := decodeBody(resp, body, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetRecentEntries returns the user's most recent entries, newest first.
+func (api *API) GetRecentEntries(limit int) ([]ExistingTimeEntry, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/user/%s/time-entries?page=1&page-size=%d", api.workspaceID, api.userID, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var entries []ExistingTimeEntry
+	if err := decodeResponse(resp, &entries); err != nil {
+		return nil, err
+	}
+
+	// Clockify already sorts newest first, but don't rely on it
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].TimeInterval.Start.After(entries[j].TimeInterval.Start)
+	})
+
+	return entries, nil
+}
+
+// GetRawDayEntries returns Clockify's response for the user's entries on the
+// given day exactly as it was received, limited to one project unless
+// projectID is empty.
+func (api *API) GetRawDayEntries(projectID string, day time.Time) ([]byte, error) {
+	params := fmt.Sprintf("?start=%s&end=%s",
+		startOfDay(day).UTC().Format(time.RFC3339),
+		endOfDay(day).UTC().Format(time.RFC3339))
+	if projectID != "" {
+		params += "&project=" + projectID
+	}
+
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/user/%s/time-entries%s", api.workspaceID, api.userID, params), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get time entries: %s - body: %s", resp.Status, string(body))
+	}
+
+	return body, nil
+}
+
+// GetDayEntries returns the user's entries on any project on the given day.
+func (api *API) GetDayEntries(day time.Time) ([]ExistingTimeEntry, error) {
+	return api.GetTimeEntries("", startOfDay(day), endOfDay(day))
+}
+
+// HasTimeEntry reports whether an entry on the project overlaps the given
+// span. Clockify is queried for the whole day and the overlap is checked
+// locally, so the result doesn't depend on how the API treats entries that
+// only partially fall inside the query window. Entries are compared with
+// the workspace's rounding applied, as Clockify shows them.
+func (api *API) HasTimeEntry(projectID string, startTime, endTime time.Time) (bool, error) {
+	entries, err := api.GetTimeEntries(projectID, startOfDay(startTime), endOfDay(startTime))
+	if err != nil {
+		return false, err
+	}
+
+	rounding, err := api.Rounding()
+	if err != nil {
+		return false, fmt.Errorf("error getting workspace rounding: %v", err)
+	}
+
+	for _, entry := range entries {
+		if rounding.Entry(entry).Overlaps(startTime, endTime) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (api *API) GetTags() ([]Tag, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/tags?page-size=5000", api.workspaceID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags []Tag
+	if err := decodeResponse(resp, &tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// FindTag returns the ID of the workspace tag with the given name, or an
+// empty string if there is no such tag.
+func (api *API) FindTag(name string) (string, error) {
+	tags, err := api.GetTags()
+	if err != nil {
+		return "", err
+	}
+
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, name) {
+			return tag.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ResolveTagIDs maps tag names to their IDs, ignoring case. Names that aren't
+// workspace tags are listed in the error.
+func (api *API) ResolveTagIDs(names []string) ([]string, error) {
+	tags, err := api.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(names))
+	var unknown []string
+	for _, name := range names {
+		index := slices.IndexFunc(tags, func(tag Tag) bool { return strings.EqualFold(tag.Name, name) })
+		if index < 0 {
+			unknown = append(unknown, name)
+			continue
+		}
+		ids = append(ids, tags[index].ID)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown tags: %s", strings.Join(unknown, ", "))
+	}
+
+	return ids, nil
+}
+
+// EnsureTag returns the ID of the workspace tag with the given name, creating
+// the tag if it doesn't exist yet.
+func (api *API) EnsureTag(name string) (string, error) {
+	if tagID, err := api.FindTag(name); err != nil || tagID != "" {
+		return tagID, err
+	}
+
+	resp, err := api.makeRequest("POST", fmt.Sprintf("/workspaces/%s/tags", api.workspaceID), Tag{Name: name})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("failed to create tag %q: %s", name, resp.Status)
+	}
+
+	var tag Tag
+	if err := decodeResponse(resp, &tag); err != nil {
+		return "", err
+	}
+
+	return tag.ID, nil
+}
+
+func (api *API) AddTimeEntry(projectID string, startTime, endTime time.Time, description string, taskID string, tagIDs []string, billable bool) error {
+	return api.addTimeEntry(context.Background(), projectID, startTime, endTime, description, taskID, tagIDs, billable)
+}
+
+func (api *API) addTimeEntry(ctx context.Context, projectID string, startTime, endTime time.Time, description string, taskID string, tagIDs []string, billable bool) error {
+	entry := newTimeEntry(projectID, startTime, endTime, description, taskID, tagIDs, billable)
+	entry.CustomFields = api.customFields
+	resp, err := api.makeRequestContext(ctx, "POST", fmt.Sprintf("/workspaces/%s/time-entries", api.workspaceID), entry)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return ErrReadOnlyKey
+	}
+	if resp.StatusCode != http.StatusCreated {
+		message := errorMessage(resp.Body)
+		if strings.Contains(strings.ToLower(message), "future") {
+			return fmt.Errorf("%w: %s", ErrFutureEntry, message)
+		}
+		if strings.Contains(strings.ToLower(message), "description") {
+			return fmt.Errorf("%w: %s", ErrDescriptionRequired, message)
+		}
+		if message != "" {
+			return fmt.Errorf("failed to create time entry: %s: %s", resp.Status, message)
+		}
+		return fmt.Errorf("failed to create time entry: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func newTimeEntry(projectID string, startTime, endTime time.Time, description string, taskID string, tagIDs []string, billable bool) TimeEntry {
+	return TimeEntry{
+		Start:       startTime.UTC().Format(time.RFC3339),
+		End:         endTime.UTC().Format(time.RFC3339),
+		Description: description,
+		ProjectID:   projectID,
+		TaskID:      taskID,
+		TagIDs:      tagIDs,
+		Billable:    billable,
+	}
+}
+
+// AddTimeEntryCurl returns a curl command that makes the same request as
+// AddTimeEntry, for replaying it by hand. The API key is left as a
+// reference to $CLOCKIFY_API_KEY.
+func (api *API) AddTimeEntryCurl(projectID string, startTime, endTime time.Time, description string, taskID string, tagIDs []string, billable bool) string {
+	entry := newTimeEntry(projectID, startTime, endTime, description, taskID, tagIDs, billable)
+	entry.CustomFields = api.customFields
+	body, _ := json.Marshal(entry)
+	quote := func(value string) string {
+		return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	}
+	return fmt.Sprintf(`curl -X POST %s -H "X-Api-Key: $CLOCKIFY_API_KEY" -H 'Content-Type: application/json' -d %s`,
+		quote(fmt.Sprintf("%s/workspaces/%s/time-entries", baseURL, api.workspaceID)), quote(string(body)))
+}
+
+// ErrFutureEntry is returned when the workspace doesn't allow time entries in
+// the future.
+var ErrFutureEntry = errors.New("workspace disallows future entries")
+
+// ErrDescriptionRequired is returned when the workspace rejects an entry for
+// its description, which happens when descriptions are required and the
+// entry's is blank.
+var ErrDescriptionRequired = errors.New("workspace requires a description")
+
+// ErrReadOnlyKey is returned when Clockify refuses to create an entry because
+// the API key can only read.
+var ErrReadOnlyKey = errors.New("your API key appears to be read-only — time entries cannot be created")
+
+// decodeResponse decodes a JSON response body into v.
+func decodeResponse(resp *http.Response, v interface{}) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %v", err)
+	}
+	return decodeBody(resp, body, v)
+}
+
+// decodeBody decodes the JSON body of resp into v. Failed or non-JSON
+// responses, such as an HTML error page during an outage or from a captive
+// portal, give an error with the status and the start of the body rather
+// than an opaque "invalid character '<'".
+func decodeBody(resp *http.Response, body []byte, v interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || (mediaType != "" && mediaType != "application/json") {
+		return fmt.Errorf("unexpected response from Clockify: %s (%s): %s", resp.Status, resp.Header.Get("Content-Type"), bodySnippet(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error decoding response (%s): %v - body: %s", resp.Status, err, bodySnippet(body))
+	}
+	return nil
+}
+
+// bodySnippet returns the start of a response body on one line, for error
+// messages.
+func bodySnippet(body []byte) string {
+	const maxLength = 200
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) > maxLength {
+		snippet = strings.ToValidUTF8(snippet[:maxLength], "") + "…"
+	}
+	return snippet
+}
+
+// errorMessage returns the message of a Clockify error response body, or an
+// empty string if it has none.
+func errorMessage(body io.Reader) string {
+	var apiError struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(body).Decode(&apiError); err != nil {
+		return ""
+	}
+	return apiError.Message
+}
+
+func (api *API) DeleteTimeEntry(entryID string) error {
+	return api.deleteTimeEntry(context.Background(), entryID)
+}
+
+func (api *API) deleteTimeEntry(ctx context.Context, entryID string) error {
+	resp, err := api.makeRequestContext(ctx, "DELETE", fmt.Sprintf("/workspaces/%s/time-entries/%s", api.workspaceID, entryID), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete time entry: %s", resp.Status)
+	}
+
+	return nil
+}
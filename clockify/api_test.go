@@ -0,0 +1,163 @@
+package clockify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAddTimeEntryEmojiDescription(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	const description = "🚀 Launch week — café"
+	start, end := at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00")
+
+	if err := api.AddTimeEntry(testProject.ID, start, end, description, "", nil, false); err != nil {
+		t.Fatalf("AddTimeEntry: %v", err)
+	}
+	posts := fake.postedBodies()
+	if len(posts) != 1 || posts[0]["description"] != description {
+		t.Fatalf("sent %v, want description %q", posts, description)
+	}
+
+	entries, err := api.GetTimeEntries(testProject.ID, start, end)
+	if err != nil {
+		t.Fatalf("GetTimeEntries: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Description != description {
+		t.Errorf("read back %+v, want description %q", entries, description)
+	}
+}
+
+func TestAddTimeEntrySendsBillableAsBool(t *testing.T) {
+	for _, billable := range []bool{true, false} {
+		fake, api := newFakeClockify(t)
+		start, end := at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00")
+		if err := api.AddTimeEntry(testProject.ID, start, end, "Work", "", nil, billable); err != nil {
+			t.Fatalf("AddTimeEntry: %v", err)
+		}
+
+		posts := fake.postedBodies()
+		if len(posts) != 1 {
+			t.Fatalf("sent %d entries, want 1", len(posts))
+		}
+		if got, ok := posts[0]["billable"].(bool); !ok || got != billable {
+			t.Errorf("sent billable %#v, want the JSON boolean %v", posts[0]["billable"], billable)
+		}
+	}
+}
+
+func TestHasTimeEntryAtDayEdges(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		entryStart time.Time
+		entryEnd   time.Time
+		checkStart time.Time
+		checkEnd   time.Time
+		want       bool
+	}{
+		{"starts at midnight", at(t, "2024-06-10", "00:00:00"), at(t, "2024-06-10", "09:30:00"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), true},
+		{"at midnight, before the span", at(t, "2024-06-10", "00:00:00"), at(t, "2024-06-10", "01:00:00"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), false},
+		{"ends at 23:59:59", at(t, "2024-06-10", "16:00:00"), at(t, "2024-06-10", "23:59:59"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), true},
+		{"at 23:59:59, after the span", at(t, "2024-06-10", "23:00:00"), at(t, "2024-06-10", "23:59:59"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), false},
+		{"previous day up to 23:59:59", at(t, "2024-06-09", "22:00:00"), at(t, "2024-06-09", "23:59:59"),
+			at(t, "2024-06-10", "00:00:00"), at(t, "2024-06-10", "09:00:00"), false},
+		{"crosses midnight into the day", at(t, "2024-06-09", "22:00:00"), at(t, "2024-06-10", "09:15:00"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), true},
+		{"crosses midnight out of the day", at(t, "2024-06-10", "16:00:00"), at(t, "2024-06-11", "02:00:00"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), true},
+		{"crosses midnight before the span", at(t, "2024-06-09", "22:00:00"), at(t, "2024-06-10", "02:00:00"),
+			at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake, api := newFakeClockify(t)
+			fake.addEntry(testProject.ID, test.entryStart, test.entryEnd)
+
+			got, err := api.HasTimeEntry(testProject.ID, test.checkStart, test.checkEnd)
+			if err != nil {
+				t.Fatalf("HasTimeEntry: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("HasTimeEntry(%s, %s) = %v, want %v", test.checkStart, test.checkEnd, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetTimeEntriesRetriesTruncatedBodyOnce(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		truncated int
+		wantErr   bool
+	}{
+		{"glitch", 1, false},
+		{"persistent", 2, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests > test.truncated {
+					w.Header().Set("Content-Type", "application/json")
+					io.WriteString(w, "[]")
+					return
+				}
+				// Promise a full body, send part of it and drop the connection
+				conn, buf, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Errorf("hijack: %v", err)
+					return
+				}
+				buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n")
+				buf.WriteString(`[{"id":"entry1","projectId":`)
+				buf.Flush()
+				conn.Close()
+			}))
+			t.Cleanup(server.Close)
+			api := newTestAPI(t, server)
+
+			day := at(t, "2024-06-10", "00:00:00")
+			entries, err := api.GetTimeEntries(testProject.ID, day, day.Add(24*time.Hour-time.Second))
+			if requests != 2 {
+				t.Errorf("sent %d requests, want 2 (one retry)", requests)
+			}
+			if test.wantErr {
+				if !errors.Is(err, errIncompleteBody) {
+					t.Errorf("got error %v, want an incomplete body", err)
+				}
+				return
+			}
+			if err != nil || len(entries) != 0 {
+				t.Errorf("got %v, %v, want no entries and no error", entries, err)
+			}
+		})
+	}
+}
+
+func TestAddTimeEntryWithoutTaskOmitsTaskID(t *testing.T) {
+	for _, taskID := range []string{"", "task1"} {
+		fake, api := newFakeClockify(t)
+		start, end := at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00")
+		if err := api.AddTimeEntry(testProject.ID, start, end, "Work", taskID, nil, false); err != nil {
+			t.Fatalf("AddTimeEntry: %v", err)
+		}
+
+		posts := fake.postedBodies()
+		if len(posts) != 1 {
+			t.Fatalf("sent %d entries, want 1", len(posts))
+		}
+		got, present := posts[0]["taskId"]
+		switch {
+		case taskID == "" && present:
+			t.Errorf("sent taskId %#v without a task, want no taskId key", got)
+		case taskID != "" && got != taskID:
+			t.Errorf("sent taskId %#v, want %q", got, taskID)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package clockify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Approval periods accepted by Clockify.
+const (
+	PeriodWeekly      = "WEEKLY"
+	PeriodSemiMonthly = "SEMI_MONTHLY"
+	PeriodMonthly     = "MONTHLY"
+)
+
+type ApprovalRequest struct {
+	ID     string `json:"id"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// PeriodStart returns the start of the approval period of the given kind
+// that contains day. Weekly periods start on weekStart, which should match
+// the workspace's week start.
+func PeriodStart(period string, day time.Time, weekStart time.Weekday) (time.Time, error) {
+	switch strings.ToUpper(period) {
+	case PeriodWeekly:
+		return WeekStart(day, weekStart), nil
+	case PeriodSemiMonthly:
+		if day.Day() >= 16 {
+			return time.Date(day.Year(), day.Month(), 16, 0, 0, 0, 0, day.Location()), nil
+		}
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location()), nil
+	case PeriodMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown approval period %q: expected weekly, semi_monthly or monthly", period)
+	}
+}
+
+// SubmitApproval submits the user's timesheet for the period starting at
+// periodStart for manager approval.
+func (api *API) SubmitApproval(period string, periodStart time.Time) (ApprovalRequest, error) {
+	body := struct {
+		Period      string `json:"period"`
+		PeriodStart string `json:"periodStart"`
+	}{
+		Period:      strings.ToUpper(period),
+		PeriodStart: time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day(), 0, 0, 0, 0, time.UTC).Format(time.RFC3339),
+	}
+
+	var approval ApprovalRequest
+	resp, err := api.makeRequest("POST", fmt.Sprintf("/workspaces/%s/approval-requests", api.workspaceID), body)
+	if err != nil {
+		return approval, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return approval, fmt.Errorf("failed to submit for approval: %s", resp.Status)
+	}
+
+	if err := decodeResponse(resp, &approval); err != nil {
+		return approval, err
+	}
+
+	return approval, nil
+}
@@ -0,0 +1,65 @@
+package clockify
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Day statuses shown in the calendar preview.
+const (
+	calendarWillFill = "✓"
+	calendarExists   = "="
+	calendarFailed   = "!"
+)
+
+// printCalendar draws each month covered by the days as a grid, marking every
+// day with its status: ✓ will be filled, = already has an entry, ! couldn't
+// be checked. Days left blank are weekends, excluded days or outside the
+// range.
+func printCalendar(out io.Writer, days []time.Time, statuses map[string]string) {
+	if len(days) == 0 {
+		return
+	}
+
+	first, last := days[0], days[0]
+	for _, day := range days {
+		if day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+
+	month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, first.Location())
+	for !month.After(last) {
+		fmt.Fprintf(out, "\n%s\n", month.Format("January 2006"))
+		fmt.Fprintln(out, " Mo  Tu  We  Th  Fr  Sa  Su")
+
+		// Monday-first column of the 1st
+		column := (int(month.Weekday()) + 6) % 7
+		var line strings.Builder
+		line.WriteString(strings.Repeat("    ", column))
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			status := statuses[day.Format("2006-01-02")]
+			if status == "" {
+				status = " "
+			}
+			fmt.Fprintf(&line, "%3d%s", day.Day(), status)
+			column++
+			if column == 7 {
+				fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+				line.Reset()
+				column = 0
+			}
+		}
+		if line.Len() > 0 {
+			fmt.Fprintln(out, strings.TrimRight(line.String(), " "))
+		}
+
+		month = month.AddDate(0, 1, 0)
+	}
+	fmt.Fprintf(out, "\n%s will fill  %s already exists  %s couldn't check\n", calendarWillFill, calendarExists, calendarFailed)
+}
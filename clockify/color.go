@@ -0,0 +1,21 @@
+package clockify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tint colors text for a terminal with a Clockify color such as "#03A9F4".
+// Text is returned unchanged if the color isn't a valid hex color.
+func Tint(text, color string) string {
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) != 6 {
+		return text
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return text
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", rgb>>16, rgb>>8&0xff, rgb&0xff, text)
+}
@@ -0,0 +1,132 @@
+package clockify
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// weekdayPosition identifies a day by its weekday and which occurrence of
+// that weekday it is within the month, e.g. the 2nd Tuesday.
+type weekdayPosition struct {
+	weekday    time.Weekday
+	occurrence int
+}
+
+func positionOf(day time.Time) weekdayPosition {
+	return weekdayPosition{weekday: day.Weekday(), occurrence: (day.Day()-1)/7 + 1}
+}
+
+// CopyMonth recreates the entries logged in sourceMonth (YYYY-MM) on the
+// target days, matching days by weekday position: the entries of the 2nd
+// Tuesday of the source month are copied to the 2nd Tuesday of the target.
+// Target days that already have an entry overlapping a copied one are skipped.
+// The marker tag, if set, is added to each copy.
+func CopyMonth(api *API, sourceMonth string, targetDays []time.Time, markerTag string, out io.Writer) (Summary, error) {
+	var summary Summary
+	if out == nil {
+		out = io.Discard
+	}
+
+	monthStart, err := time.ParseInLocation("2006-01", sourceMonth, time.Local)
+	if err != nil {
+		return summary, fmt.Errorf("invalid month %q: expected YYYY-MM", sourceMonth)
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Second)
+
+	entries, err := api.GetTimeEntries("", monthStart, monthEnd)
+	if err != nil {
+		return summary, err
+	}
+
+	// Group the source entries by the weekday position of their day
+	byPosition := make(map[weekdayPosition][]ExistingTimeEntry)
+	sourceDays := make(map[weekdayPosition]time.Time)
+	for _, entry := range entries {
+		if entry.TimeInterval.End == nil {
+			continue
+		}
+		start := entry.TimeInterval.Start.Local()
+		position := positionOf(start)
+		byPosition[position] = append(byPosition[position], entry)
+		sourceDays[position] = start
+	}
+
+	if len(byPosition) == 0 {
+		fmt.Fprintf(out, "No entries found in %s\n", sourceMonth)
+		return summary, nil
+	}
+
+	// Warn about source days that have no counterpart in the target month
+	targetMonth := time.Now()
+	if len(targetDays) > 0 {
+		targetMonth = targetDays[0]
+	}
+	var unmatched []time.Time
+	for position, day := range sourceDays {
+		if !monthHasPosition(targetMonth.Year(), targetMonth.Month(), position) {
+			unmatched = append(unmatched, day)
+		}
+	}
+	sort.Slice(unmatched, func(i, j int) bool { return unmatched[i].Before(unmatched[j]) })
+	for _, day := range unmatched {
+		fmt.Fprintf(out, "Warning: %s has no matching day in %s, its entries will not be copied\n",
+			day.Format("2006-01-02"), targetMonth.Format("January 2006"))
+	}
+
+	tagIDs := markerTagIDs(out, api, markerTag)
+	for _, day := range targetDays {
+		for _, entry := range byPosition[positionOf(day)] {
+			start := onDay(day, entry.TimeInterval.Start.Local())
+			end := onDay(day, entry.TimeInterval.End.Local())
+
+			hasEntry, err := api.HasTimeEntry(entry.ProjectID, start, end)
+			if err != nil {
+				fmt.Fprintf(out, "Error checking time entry for %s: %v\n", day.Format("2006-01-02"), err)
+				summary.Failed++
+				continue
+			}
+			if hasEntry {
+				fmt.Fprintf(out, "Skipping %s %s-%s - Time entry already exists\n",
+					day.Format("2006-01-02"), start.Format("15:04"), end.Format("15:04"))
+				summary.Skipped++
+				continue
+			}
+
+			entryTagIDs := append([]string(nil), entry.TagIDs...)
+			for _, tagID := range tagIDs {
+				if !entry.HasTag(tagID) {
+					entryTagIDs = append(entryTagIDs, tagID)
+				}
+			}
+
+			if err := api.AddTimeEntry(entry.ProjectID, start, end, entry.Description, entry.TaskID, entryTagIDs, entry.Billable); err != nil {
+				fmt.Fprintf(out, "Failed to add time entry for %s: %v\n", day.Format("2006-01-02"), err)
+				summary.Failed++
+				continue
+			}
+
+			fmt.Fprintf(out, "Added time entry for %s %s-%s (copied from %s)\n", day.Format("2006-01-02"),
+				start.Format("15:04"), end.Format("15:04"), entry.TimeInterval.Start.Local().Format("2006-01-02"))
+			summary.Added++
+			summary.AddedTime += end.Sub(start)
+		}
+	}
+
+	return summary, nil
+}
+
+// onDay returns the time of day of t on the given day.
+func onDay(day, t time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, day.Location())
+}
+
+func monthHasPosition(year int, month time.Month, position weekdayPosition) bool {
+	for day := time.Date(year, month, 1, 0, 0, 0, 0, time.Local); day.Month() == month; day = day.AddDate(0, 0, 1) {
+		if positionOf(day) == position {
+			return true
+		}
+	}
+	return false
+}
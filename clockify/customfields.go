@@ -0,0 +1,74 @@
+package clockify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CustomField is a workspace custom field that entries can carry.
+type CustomField struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CustomFieldValue is the value of a custom field on an entry.
+type CustomFieldValue struct {
+	CustomFieldID string      `json:"customFieldId"`
+	Value         interface{} `json:"value"`
+}
+
+// FindCustomField returns the workspace custom field with the given name,
+// ignoring case.
+func (api *API) FindCustomField(name string) (CustomField, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/custom-fields", api.workspaceID), nil)
+	if err != nil {
+		return CustomField{}, err
+	}
+	defer resp.Body.Close()
+
+	var fields []CustomField
+	if err := decodeResponse(resp, &fields); err != nil {
+		return CustomField{}, err
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.Name, name) {
+			return field, nil
+		}
+	}
+	return CustomField{}, fmt.Errorf("custom field %q not found in the workspace", name)
+}
+
+// SetSourceField makes every entry created from now on carry value in the
+// named custom field, e.g. Source=ClockiFill, so reports can tell them
+// apart from manual entries.
+func (api *API) SetSourceField(name, value string) error {
+	field, err := api.FindCustomField(name)
+	if err != nil {
+		return err
+	}
+	api.customFields = []CustomFieldValue{{CustomFieldID: field.ID, Value: value}}
+	return nil
+}
+
+// HasCustomFieldValue reports whether the entry's custom field holds value,
+// compared as text ignoring case.
+func (e ExistingTimeEntry) HasCustomFieldValue(fieldID, value string) bool {
+	for _, field := range e.CustomFieldValues {
+		if field.CustomFieldID == fieldID && field.Value != nil && strings.EqualFold(fmt.Sprint(field.Value), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSourceFields reports whether the entry carries the values set by
+// SetSourceField; every entry does if none are set.
+func (api *API) hasSourceFields(entry ExistingTimeEntry) bool {
+	for _, field := range api.customFields {
+		if !entry.HasCustomFieldValue(field.CustomFieldID, fmt.Sprint(field.Value)) {
+			return false
+		}
+	}
+	return true
+}
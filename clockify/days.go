@@ -0,0 +1,108 @@
+package clockify
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// GetWorkingDays returns the weekdays from startDate's date up to and
+// including endDate's date, each at midnight. Only the dates matter, so the
+// result doesn't depend on the times of day passed in.
+func GetWorkingDays(startDate, endDate time.Time) []time.Time {
+	var workingDays []time.Time
+	last := startOfDay(endDate)
+
+	for currentDate := startOfDay(startDate); !currentDate.After(last); currentDate = currentDate.AddDate(0, 0, 1) {
+		if currentDate.Weekday() != time.Saturday && currentDate.Weekday() != time.Sunday {
+			workingDays = append(workingDays, currentDate)
+		}
+	}
+
+	return workingDays
+}
+
+// ExcludeDays removes the days whose date appears in excluded, which maps
+// YYYY-MM-DD to the reason the day is not worked, reporting each to out.
+func ExcludeDays(days []time.Time, excluded map[string]string, out io.Writer) []time.Time {
+	var remaining []time.Time
+	for _, day := range days {
+		if reason, ok := excluded[day.Format("2006-01-02")]; ok {
+			fmt.Fprintf(out, "Skipping %s - %s\n", day.Format("2006-01-02"), reason)
+			continue
+		}
+		remaining = append(remaining, day)
+	}
+	return remaining
+}
+
+// IncludeDays adds the forced days to days, even weekends and days that were
+// excluded, reporting each one added to out. The result is in chronological
+// order without repeats.
+func IncludeDays(days, forced []time.Time, out io.Writer) []time.Time {
+	present := make(map[string]bool)
+	for _, day := range days {
+		present[day.Format("2006-01-02")] = true
+	}
+
+	for _, day := range forced {
+		date := day.Format("2006-01-02")
+		if present[date] {
+			continue
+		}
+		present[date] = true
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			fmt.Fprintf(out, "Including weekend %s (forced)\n", date)
+		} else {
+			fmt.Fprintf(out, "Including %s (forced)\n", date)
+		}
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// PreviousWeek returns the start of the first day and the end of the last
+// day of the last full week before the one containing now, for weeks
+// starting on weekStart.
+func PreviousWeek(now time.Time, weekStart time.Weekday) (time.Time, time.Time) {
+	thisWeek := WeekStart(now, weekStart)
+	return thisWeek.AddDate(0, 0, -7), endOfDay(thisWeek.AddDate(0, 0, -1))
+}
+
+// WeekStart returns the start of the week containing day, for weeks starting
+// on weekStart.
+func WeekStart(day time.Time, weekStart time.Weekday) time.Time {
+	daysSinceStart := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return startOfDay(day).AddDate(0, 0, -daysSinceStart)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// clockTime formats a time of day given as an offset from midnight, e.g.
+// "09:30".
+func clockTime(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// FormatDuration formats a duration compactly, e.g. "1h", "45m" or "1h30m".
+func FormatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+}
@@ -0,0 +1,37 @@
+package clockify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekStartSundayAcrossMonths(t *testing.T) {
+	for _, date := range []string{"2024-06-30", "2024-07-01", "2024-07-03", "2024-07-06"} {
+		day := at(t, date, "12:00:00")
+		if got := WeekStart(day, time.Sunday).Format("2006-01-02 15:04:05"); got != "2024-06-30 00:00:00" {
+			t.Errorf("week of %s starts %s, want 2024-06-30 00:00:00", date, got)
+		}
+	}
+	if got := WeekStart(at(t, "2024-07-07", "00:00:00"), time.Sunday).Format("2006-01-02"); got != "2024-07-07" {
+		t.Errorf("week of 2024-07-07 starts %s, want 2024-07-07", got)
+	}
+}
+
+func TestPreviousWeekAcrossMonths(t *testing.T) {
+	now := at(t, "2024-07-10", "15:00:00")
+	for _, test := range []struct {
+		weekStart time.Weekday
+		from, to  string
+	}{
+		{time.Sunday, "2024-06-30 00:00:00", "2024-07-06 23:59:59"},
+		{time.Monday, "2024-07-01 00:00:00", "2024-07-07 23:59:59"},
+	} {
+		from, to := PreviousWeek(now, test.weekStart)
+		if got := from.Format("2006-01-02 15:04:05"); got != test.from {
+			t.Errorf("%s weeks: previous week starts %s, want %s", test.weekStart, got, test.from)
+		}
+		if got := to.Format("2006-01-02 15:04:05"); got != test.to {
+			t.Errorf("%s weeks: previous week ends %s, want %s", test.weekStart, got, test.to)
+		}
+	}
+}
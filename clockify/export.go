@@ -0,0 +1,75 @@
+package clockify
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CalendarExport collects planned entries, possibly from several Fill calls,
+// so the plan can be reviewed in a calendar app before anything is created.
+type CalendarExport struct {
+	events []exportEvent
+}
+
+type exportEvent struct {
+	project     string
+	description string
+	start, end  time.Time
+}
+
+func (c *CalendarExport) add(project string, entries []plannedEntry) {
+	for _, entry := range entries {
+		c.events = append(c.events, exportEvent{project, entry.Description, entry.Start, entry.End})
+	}
+}
+
+// Len returns the number of entries collected.
+func (c *CalendarExport) Len() int {
+	return len(c.events)
+}
+
+// Write writes the collected entries as an iCalendar (RFC 5545) file, one
+// VEVENT per entry with the description as its summary.
+func (c *CalendarExport) Write(w io.Writer) error {
+	out := bufio.NewWriter(w)
+	line := func(text string) {
+		// Lines longer than 75 bytes are folded onto continuation lines
+		for len(text) > 75 {
+			cut := 75
+			for cut > 1 && !utf8Start(text[cut]) {
+				cut--
+			}
+			out.WriteString(text[:cut] + "\r\n")
+			text = " " + text[cut:]
+		}
+		out.WriteString(text + "\r\n")
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ClockiFill//Plan//EN")
+	for i, event := range c.events {
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%s-%d@clockifill", event.start.UTC().Format("20060102T150405Z"), i))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + event.start.UTC().Format("20060102T150405Z"))
+		line("DTEND:" + event.end.UTC().Format("20060102T150405Z"))
+		line("SUMMARY:" + escapeCalendarText(event.description))
+		line("DESCRIPTION:" + escapeCalendarText("Project: "+event.project))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return out.Flush()
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func escapeCalendarText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,226 @@
+package clockify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClockify is an in-memory stand-in for the parts of the Clockify API
+// that Fill uses.
+type fakeClockify struct {
+	t *testing.T
+
+	mu      sync.Mutex
+	entries []ExistingTimeEntry
+	tags    []Tag
+	tasks   map[string][]Task
+	fields  []CustomField
+	// settings is the workspaceSettings JSON of the workspace.
+	settings string
+	// posts are the bodies of the created entries, in the order received.
+	posts  []map[string]interface{}
+	nextID int
+}
+
+// newFakeClockify starts a fake Clockify for the test and returns it with an
+// API client pointed at it.
+func newFakeClockify(t *testing.T) (*fakeClockify, *API) {
+	fake := &fakeClockify{t: t, tasks: make(map[string][]Task), settings: "{}"}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, newTestAPI(t, server)
+}
+
+// newTestAPI returns an API client that sends its requests to server for
+// the duration of the test.
+func newTestAPI(t *testing.T, server *httptest.Server) *API {
+	previous := baseURL
+	baseURL = server.URL
+	t.Cleanup(func() { baseURL = previous })
+	return &API{apiKey: "key", workspaceID: "ws", userID: "user", client: server.Client()}
+}
+
+// setInjectFault installs fault as the injectFault hook for the duration of
+// the test.
+func setInjectFault(t *testing.T, fault func(req *http.Request) (*http.Response, error)) {
+	previous := injectFault
+	injectFault = fault
+	t.Cleanup(func() { injectFault = previous })
+}
+
+func (f *fakeClockify) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	path := r.URL.Path
+	switch {
+	case r.Method == "GET" && path == "/workspaces/ws/user/user/time-entries":
+		start, _ := time.Parse(time.RFC3339, r.URL.Query().Get("start"))
+		end, _ := time.Parse(time.RFC3339, r.URL.Query().Get("end"))
+		project := r.URL.Query().Get("project")
+		matches := []ExistingTimeEntry{}
+		for _, entry := range f.entries {
+			if (project == "" || entry.ProjectID == project) && entry.Overlaps(start, end) {
+				matches = append(matches, entry)
+			}
+		}
+		json.NewEncoder(w).Encode(matches)
+
+	case r.Method == "GET" && path == "/workspaces/ws/tags":
+		json.NewEncoder(w).Encode(append([]Tag{}, f.tags...))
+
+	case r.Method == "POST" && path == "/workspaces/ws/tags":
+		var tag Tag
+		json.NewDecoder(r.Body).Decode(&tag)
+		f.nextID++
+		tag.ID = fmt.Sprintf("tag%d", f.nextID)
+		f.tags = append(f.tags, tag)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(tag)
+
+	case r.Method == "GET" && path == "/workspaces/ws/custom-fields":
+		json.NewEncoder(w).Encode(append([]CustomField{}, f.fields...))
+
+	case r.Method == "GET" && path == "/workspaces/ws":
+		fmt.Fprintf(w, `{"workspaceSettings":%s}`, f.settings)
+
+	case r.Method == "GET" && strings.HasPrefix(path, "/workspaces/ws/projects/") && strings.HasSuffix(path, "/tasks"):
+		projectID := strings.TrimSuffix(strings.TrimPrefix(path, "/workspaces/ws/projects/"), "/tasks")
+		json.NewEncoder(w).Encode(append([]Task{}, f.tasks[projectID]...))
+
+	case r.Method == "POST" && path == "/workspaces/ws/time-entries":
+		body, _ := io.ReadAll(r.Body)
+		var fields map[string]interface{}
+		var entry TimeEntry
+		if err := json.Unmarshal(body, &fields); err != nil {
+			f.t.Errorf("invalid time entry body %s: %v", body, err)
+		}
+		json.Unmarshal(body, &entry)
+		f.posts = append(f.posts, fields)
+
+		start, _ := time.Parse(time.RFC3339, entry.Start)
+		end, _ := time.Parse(time.RFC3339, entry.End)
+		created := f.entry(entry.ProjectID, start, end, entry.TagIDs...)
+		created.Description = entry.Description
+		created.TaskID = entry.TaskID
+		created.Billable = entry.Billable
+		f.entries = append(f.entries, created)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	case r.Method == "DELETE" && strings.HasPrefix(path, "/workspaces/ws/time-entries/"):
+		id := strings.TrimPrefix(path, "/workspaces/ws/time-entries/")
+		for i, entry := range f.entries {
+			if entry.ID == id {
+				f.entries = append(f.entries[:i], f.entries[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == "GET" && path == "/user":
+		io.WriteString(w, `{"id":"user","memberships":[]}`)
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		http.NotFound(w, r)
+	}
+}
+
+// entry returns a new entry with an ID of its own. The caller must hold mu
+// or not have started any requests yet.
+func (f *fakeClockify) entry(projectID string, start, end time.Time, tagIDs ...string) ExistingTimeEntry {
+	f.nextID++
+	entry := ExistingTimeEntry{ID: fmt.Sprintf("entry%d", f.nextID), ProjectID: projectID, TagIDs: tagIDs}
+	entry.TimeInterval.Start = start
+	entry.TimeInterval.End = &end
+	return entry
+}
+
+// addEntry adds an existing entry to the workspace.
+func (f *fakeClockify) addEntry(projectID string, start, end time.Time, tagIDs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, f.entry(projectID, start, end, tagIDs...))
+}
+
+// postedBodies returns the bodies of the created entries so far.
+func (f *fakeClockify) postedBodies() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}{}, f.posts...)
+}
+
+// postedDates returns the dates of the created entries, in the order they
+// were created.
+func (f *fakeClockify) postedDates() []string {
+	var dates []string
+	for _, body := range f.postedBodies() {
+		start, _ := body["start"].(string)
+		dates = append(dates, strings.Split(start, "T")[0])
+	}
+	return dates
+}
+
+// testProject is the project the tests fill.
+var testProject = Project{ID: "project1", Name: "Project"}
+
+// testDays returns the given dates, YYYY-MM-DD, as days in UTC.
+func testDays(t *testing.T, dates ...string) []time.Time {
+	var days []time.Time
+	for _, date := range dates {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// at returns the time on date, YYYY-MM-DD, at clock, HH:MM:SS, in UTC.
+func at(t *testing.T, date, clock string) time.Time {
+	when, err := time.Parse("2006-01-02 15:04:05", date+" "+clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return when
+}
+
+func TestInjectFaultRetries(t *testing.T) {
+	_, api := newFakeClockify(t)
+	api.maxRetries = 2
+
+	rateLimited := 0
+	setInjectFault(t, func(req *http.Request) (*http.Response, error) {
+		if rateLimited == 2 {
+			return nil, nil
+		}
+		rateLimited++
+		header := make(http.Header)
+		header.Set("Retry-After", "0")
+		return &http.Response{
+			Status:     "429 Too Many Requests",
+			StatusCode: http.StatusTooManyRequests,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+
+	if _, err := api.GetTags(); err != nil {
+		t.Fatalf("GetTags: %v", err)
+	}
+	stats := api.RetryStats()
+	if stats.Retries != 2 || stats.RateLimitWaits != 2 {
+		t.Errorf("got %d retries with %d rate-limit waits, want 2 and 2", stats.Retries, stats.RateLimitWaits)
+	}
+}
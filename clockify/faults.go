@@ -0,0 +1,117 @@
+//go:build simulate_errors
+
+package clockify
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Building with -tags simulate_errors lets CLOCKIFILL_SIMULATE_ERRORS inject
+// synthetic failures into makeRequest, for exercising the error handling
+// paths without a flaky network. The variable holds comma-separated
+// kind=rate pairs, where kind is an HTTP status code, "timeout" or "empty"
+// (a 200 with no body, for reads only) and rate is the probability (0-1) of
+// each request failing that way, e.g.
+//
+//	CLOCKIFILL_SIMULATE_ERRORS=500=0.1,429=0.2,timeout=0.05,empty=0.1
+//
+// CLOCKIFILL_SIMULATE_SEED seeds the random source so runs are reproducible.
+func init() {
+	spec := os.Getenv("CLOCKIFILL_SIMULATE_ERRORS")
+	if spec == "" {
+		return
+	}
+
+	faults, err := parseFaultSpec(spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid CLOCKIFILL_SIMULATE_ERRORS: %v\n", err)
+		os.Exit(2)
+	}
+
+	seed, _ := strconv.ParseInt(os.Getenv("CLOCKIFILL_SIMULATE_SEED"), 10, 64)
+	random := rand.New(rand.NewSource(seed))
+	var mu sync.Mutex
+
+	injectFault = func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		roll := random.Float64()
+		mu.Unlock()
+		for _, fault := range faults {
+			if roll >= fault.rate {
+				roll -= fault.rate
+				continue
+			}
+			switch {
+			case fault.kind == "timeout":
+				return nil, fmt.Errorf("%s %s: simulated timeout: %w", req.Method, req.URL, os.ErrDeadlineExceeded)
+			case fault.kind == "empty" && req.Method == "GET":
+				return simulatedResponse(req, http.StatusOK), nil
+			case fault.kind == "empty":
+				return nil, nil
+			}
+			return simulatedResponse(req, fault.status), nil
+		}
+		return nil, nil
+	}
+}
+
+type fault struct {
+	kind   string
+	status int
+	rate   float64
+}
+
+func parseFaultSpec(spec string) ([]fault, error) {
+	var faults []fault
+	for _, item := range strings.Split(spec, ",") {
+		kind, rateValue, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected kind=rate, got %q", item)
+		}
+
+		rate, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid rate %q for %s", rateValue, kind)
+		}
+
+		f := fault{kind: strings.ToLower(kind), rate: rate}
+		if f.kind != "timeout" && f.kind != "empty" {
+			if f.status, err = strconv.Atoi(kind); err != nil || f.status < 400 || f.status > 599 {
+				return nil, fmt.Errorf("invalid kind %q: expected an HTTP error status, \"timeout\" or \"empty\"", kind)
+			}
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+func simulatedResponse(req *http.Request, status int) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	body := `{"message":"simulated error","code":` + strconv.Itoa(status) + `}`
+	if status == http.StatusOK {
+		body = ""
+	}
+	if status == http.StatusTooManyRequests {
+		header.Set("Retry-After", "1")
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,1038 @@
+package clockify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDescription is used for entries when no description is configured.
+const DefaultDescription = "Standard workday"
+
+// FillOptions configures a Fill run.
+type FillOptions struct {
+	API *API
+
+	// Days are the working days to fill.
+	Days []time.Time
+
+	Project  Project
+	TaskID   string
+	Billable bool
+
+	// StartTime and EndTime are the times of day each entry starts and
+	// ends, as offsets from midnight. Zero means 09:00 and 16:30 unless
+	// ExactTimes is set, which makes zero midnight.
+	StartTime  time.Duration
+	EndTime    time.Duration
+	ExactTimes bool
+
+	// PrefixTaskName starts each description with "[TaskName] " when a task
+	// is selected, so exported reports show the task.
+	TaskName       string
+	PrefixTaskName bool
+
+	// Description is used for every entry; it defaults to DefaultDescription.
+	Description string
+	// DescriptionCycle, if set, assigns these descriptions round-robin
+	// across Days instead.
+	DescriptionCycle []string
+	// DatedDescriptions maps dates (YYYY-MM-DD) to the description for that
+	// day, taking precedence over the other description settings.
+	DatedDescriptions map[string]string
+	// DescriptionPrompt, if set, is asked for each day's description, with
+	// the previous day's description offered as the default.
+	DescriptionPrompt func(day time.Time, previous string) string
+	// ExpandEnv expands $VAR and ${VAR} references in descriptions from the
+	// environment.
+	ExpandEnv bool
+	// FallbackDescription, if set, is used to retry an entry the workspace
+	// rejected for its description, e.g. because descriptions are required.
+	FallbackDescription string
+
+	// BreakNote notes an unpaid break of this length in each description;
+	// with DeductBreak the entry is also shortened by it.
+	BreakNote   time.Duration
+	DeductBreak bool
+
+	// WeeklyHours, if set, adjusts the entry on the last of Days in each
+	// week so the time logged on the week's Days, existing plus new, comes
+	// to exactly this many hours. Weeks start on WeekStart.
+	WeeklyHours float64
+	WeekStart   time.Weekday
+
+	// DayFractions maps dates (YYYY-MM-DD) of partial working days to the
+	// fraction of the full day to log, e.g. 0.5. Those entries keep their
+	// start time and have no break.
+	DayFractions map[string]float64
+
+	// KeepDuration makes each entry last exactly EndTime-StartTime of
+	// elapsed time, ending that long after its start, instead of ending at
+	// the EndTime wall-clock time. They differ when a daylight saving
+	// change falls within the entry.
+	KeepDuration bool
+
+	// PreviewCalendar draws the plan as a month calendar before anything is
+	// created.
+	PreviewCalendar bool
+
+	// ExpectedDailyHours, if set, is compared with the length of each
+	// day's entry and a warning is printed when they differ.
+	ExpectedDailyHours float64
+
+	// SecondsJitter offsets each entry's start and end by up to this much,
+	// reproducibly for a given JitterSeed.
+	SecondsJitter time.Duration
+	JitterSeed    int64
+	// StartJitter moves each entry, start and end together, by up to this
+	// much either way in whole minutes, so the length is unchanged. It is
+	// also reproducible for a given JitterSeed.
+	StartJitter time.Duration
+
+	// DraftTag, if set, is attached to every created entry so unreviewed
+	// entries can be found and finalized in Clockify, which has no draft
+	// state of its own.
+	DraftTag string
+	// TagIDs are attached to every created entry as well.
+	TagIDs []string
+
+	// MarkerTag is attached to every created entry to identify it as
+	// ClockiFill's own. Days with a marked entry are not filled again
+	// unless DuplicateOK is set.
+	MarkerTag   string
+	DuplicateOK bool
+	// OnlyEmptyDays fills only days with no time logged on any project.
+	OnlyEmptyDays bool
+	// TargetHours, if set, stops planning days once the time logged on Days
+	// (on any project) plus the planned entries reaches this many hours.
+	TargetHours float64
+	// DryRun plans and previews the entries without creating anything;
+	// with NoSkip the days that would be skipped are listed too, with the
+	// entry they would have got.
+	DryRun bool
+	NoSkip bool
+	// DumpRequests prints, in a dry run, the curl command that would create
+	// each entry. The marker and draft tags are created for it if missing.
+	DumpRequests bool
+	// Export, if set, collects the planned entries for writing as an
+	// iCalendar file.
+	Export *CalendarExport
+
+	// Append creates the entries even on days with existing or overlapping
+	// entries, for deliberately stacking a second block on a day.
+	Append bool
+	// RecreateDates are days whose ClockiFill entries are deleted and
+	// created afresh with the current settings instead of being skipped.
+	RecreateDates []time.Time
+
+	// Reverse creates the entries from the newest day to the oldest, so the
+	// most recent day comes first in Clockify's activity feed.
+	Reverse bool
+
+	// VerifyAfter reads every created entry back afterwards and reports
+	// any that are missing or have the wrong duration.
+	VerifyAfter bool
+
+	// TimeoutPerDay, if set, limits how long creating one day's entry may
+	// take in total, retries included, before the day counts as failed.
+	TimeoutPerDay time.Duration
+
+	// ReadConcurrency is how many days are checked for existing entries at
+	// once, and WriteConcurrency how many entries are created at once.
+	// Zero means one at a time.
+	ReadConcurrency  int
+	WriteConcurrency int
+
+	// Output receives progress messages; nil discards them.
+	Output io.Writer
+	// Color tints the project name in Output with its Clockify color.
+	Color bool
+}
+
+// maxPaddedDay is the longest an entry lengthened by WeeklyHours may get
+// before the adjustment is refused.
+const maxPaddedDay = 12 * time.Hour
+
+// dailyHoursTolerance is how far the configured entry length may be from the
+// expected daily hours before Fill warns about it.
+const dailyHoursTolerance = 5 * time.Minute
+
+// Summary counts the outcome of a run.
+type Summary struct {
+	Added int
+	// AddedTime is the total length of the added entries.
+	AddedTime time.Duration
+	Skipped   int
+	Failed    int
+	// Unverified counts added entries that VerifyAfter couldn't find as
+	// created.
+	Unverified int
+	// DryRun means nothing was created; Added counts the entries that
+	// would have been.
+	DryRun bool
+	// FutureRejected counts entries the workspace refused for being in the
+	// future, usually a sign of a wrong clock or range.
+	FutureRejected int
+	// Locked counts days skipped for being before the workspace's lock
+	// date.
+	Locked int
+	// TargetReached counts days left empty because TargetHours was already
+	// met.
+	TargetReached int
+}
+
+// Add adds the counts of another run.
+func (s *Summary) Add(other Summary) {
+	s.Added += other.Added
+	s.AddedTime += other.AddedTime
+	s.Skipped += other.Skipped
+	s.Failed += other.Failed
+	s.Unverified += other.Unverified
+	s.FutureRejected += other.FutureRejected
+	s.Locked += other.Locked
+	s.TargetReached += other.TargetReached
+	s.DryRun = s.DryRun || other.DryRun
+}
+
+func (s Summary) String() string {
+	summary := fmt.Sprintf("Added %d entries, Skipped %d existing entries", s.Added, s.Skipped)
+	if s.DryRun {
+		summary = fmt.Sprintf("Dry run: would add %d entries, Skipped %d existing entries", s.Added, s.Skipped)
+	}
+	if s.Failed > 0 {
+		summary += fmt.Sprintf(", Failed %d", s.Failed)
+	}
+	if s.Unverified > 0 {
+		summary += fmt.Sprintf(", Unverified %d", s.Unverified)
+	}
+	if s.FutureRejected > 0 {
+		summary += fmt.Sprintf(", Rejected as future %d (check the system clock and date range)", s.FutureRejected)
+	}
+	if s.Locked > 0 {
+		summary += fmt.Sprintf(", Locked %d (before the workspace's lock date)", s.Locked)
+	}
+	if s.TargetReached > 0 {
+		summary += fmt.Sprintf(", Not needed %d (target reached)", s.TargetReached)
+	}
+	return summary
+}
+
+// Validate checks all the options at once and returns an error listing every
+// problem found, or nil if the options are usable.
+func (opts FillOptions) Validate() error {
+	var problems []error
+	if opts.API == nil {
+		problems = append(problems, errors.New("API is required"))
+	}
+	if opts.Project.ID == "" {
+		problems = append(problems, errors.New("project is required"))
+	}
+	if len(opts.DescriptionCycle) > 0 && opts.DescriptionPrompt != nil {
+		problems = append(problems, errors.New("description cycle and description prompt are mutually exclusive"))
+	}
+	for i, description := range opts.DescriptionCycle {
+		if strings.TrimSpace(description) == "" {
+			problems = append(problems, fmt.Errorf("description cycle item %d is empty", i+1))
+		}
+	}
+	for i := 1; i < len(opts.Days); i++ {
+		if !startOfDay(opts.Days[i-1]).Before(startOfDay(opts.Days[i])) {
+			problems = append(problems, fmt.Errorf("days must be in chronological order without repeats: %s follows %s",
+				opts.Days[i].Format("2006-01-02"), opts.Days[i-1].Format("2006-01-02")))
+			break
+		}
+	}
+	if opts.TargetHours < 0 {
+		problems = append(problems, fmt.Errorf("target hours must not be negative, got %g", opts.TargetHours))
+	}
+	if opts.BreakNote < 0 {
+		problems = append(problems, fmt.Errorf("break note must not be negative, got %s", opts.BreakNote))
+	}
+	if opts.DeductBreak && opts.BreakNote == 0 {
+		problems = append(problems, errors.New("deducting the break requires a break note length"))
+	}
+	if opts.DeductBreak && opts.dailyDuration() <= 0 {
+		problems = append(problems, fmt.Errorf("break of %s would end the entry before it starts", FormatDuration(opts.BreakNote)))
+	}
+	if len(opts.RecreateDates) > 0 && opts.MarkerTag == "" {
+		problems = append(problems, errors.New("recreating dates requires the marker tag to find ClockiFill's entries"))
+	}
+	if opts.WeeklyHours < 0 || opts.WeeklyHours > 7*24 {
+		problems = append(problems, fmt.Errorf("weekly hours must be between 0 and 168, got %g", opts.WeeklyHours))
+	}
+	if opts.ExpectedDailyHours < 0 || opts.ExpectedDailyHours > 24 {
+		problems = append(problems, fmt.Errorf("expected daily hours must be between 0 and 24, got %g", opts.ExpectedDailyHours))
+	}
+	for date, fraction := range opts.DayFractions {
+		if fraction <= 0 || fraction > 1 {
+			problems = append(problems, fmt.Errorf("fraction %g for %s must be more than 0 and at most 1", fraction, date))
+		}
+	}
+	if opts.StartJitter < 0 {
+		problems = append(problems, fmt.Errorf("start jitter must not be negative, got %s", opts.StartJitter))
+	}
+	if opts.SecondsJitter < 0 {
+		problems = append(problems, fmt.Errorf("seconds jitter must not be negative, got %s", opts.SecondsJitter))
+	}
+	if start, end := opts.entryTimes(); start < 0 || end > 24*time.Hour || start >= end {
+		problems = append(problems, fmt.Errorf("entries must start before they end within the day, got %s to %s", clockTime(start), clockTime(end)))
+	}
+	if opts.TimeoutPerDay < 0 {
+		problems = append(problems, fmt.Errorf("timeout per day must not be negative, got %s", opts.TimeoutPerDay))
+	}
+	if opts.ReadConcurrency < 0 || opts.WriteConcurrency < 0 {
+		problems = append(problems, fmt.Errorf("concurrency must not be negative, got %d reads and %d writes", opts.ReadConcurrency, opts.WriteConcurrency))
+	}
+	return errors.Join(problems...)
+}
+
+// dailyDuration returns the length of each day's entry, after any break is
+// deducted.
+func (opts FillOptions) dailyDuration() time.Duration {
+	start, end := opts.entryTimes()
+	daily := end - start
+	if opts.DeductBreak {
+		daily -= opts.BreakNote
+	}
+	return daily
+}
+
+// Default working hours.
+const (
+	DefaultStartTime = 9 * time.Hour
+	DefaultEndTime   = 16*time.Hour + 30*time.Minute
+)
+
+// entryTimes returns the configured start and end times of day.
+func (opts FillOptions) entryTimes() (time.Duration, time.Duration) {
+	start, end := opts.StartTime, opts.EndTime
+	if opts.ExactTimes {
+		return start, end
+	}
+	if start == 0 {
+		start = DefaultStartTime
+	}
+	if end == 0 {
+		end = DefaultEndTime
+	}
+	return start, end
+}
+
+// atTimeOfDay returns the given time of day on day's date, in wall-clock time.
+func atTimeOfDay(day time.Time, offset time.Duration) time.Time {
+	seconds := int(offset / time.Second)
+	return time.Date(day.Year(), day.Month(), day.Day(), seconds/3600, seconds/60%60, seconds%60, 0, day.Location())
+}
+
+type plannedEntry struct {
+	Day         time.Time
+	Start       time.Time
+	End         time.Time
+	Description string
+	// Replaces are existing entries deleted before this one is created.
+	Replaces []ExistingTimeEntry
+	// Label names the entry in progress lines; the date if empty.
+	Label string
+}
+
+func (entry plannedEntry) label() string {
+	if entry.Label != "" {
+		return entry.Label
+	}
+	return entry.Day.Format("2006-01-02")
+}
+
+// Fill creates an entry (09:00-16:30 unless configured) on each of the given
+// days that doesn't already have one, after printing a preview of the plan.
+func Fill(opts FillOptions) (Summary, error) {
+	var summary Summary
+	if err := opts.Validate(); err != nil {
+		return summary, fmt.Errorf("invalid options:\n%v", err)
+	}
+
+	api := opts.API
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	defaultDescription := opts.Description
+	if defaultDescription == "" {
+		defaultDescription = DefaultDescription
+	}
+
+	if opts.ExpectedDailyHours > 0 {
+		daily := opts.dailyDuration()
+		expected := time.Duration(opts.ExpectedDailyHours * float64(time.Hour))
+		if diff := daily - expected; diff > dailyHoursTolerance || diff < -dailyHoursTolerance {
+			fmt.Fprintf(out, "Warning: entries will be %s per day, but %s is expected\n",
+				FormatDuration(daily), FormatDuration(expected))
+		}
+	}
+
+	// Clockify's error for a task on another project is confusing, so the
+	// pair is checked before anything is created
+	if opts.TaskID != "" {
+		tasks, err := api.GetTasks(opts.Project.ID)
+		if err != nil {
+			return summary, fmt.Errorf("error getting tasks of project %s: %v", opts.Project.Name, err)
+		}
+		if _, err := FindTaskByID(tasks, opts.TaskID, opts.Project); err != nil {
+			return summary, err
+		}
+	}
+
+	// Look up the marker tag so days already filled by ClockiFill are skipped
+	markerTagID := ""
+	if opts.MarkerTag != "" {
+		var err error
+		if markerTagID, err = api.FindTag(opts.MarkerTag); err != nil {
+			return summary, fmt.Errorf("error looking up marker tag %q: %v", opts.MarkerTag, err)
+		}
+	}
+
+	recreate := make(map[string]bool)
+	for _, day := range opts.RecreateDates {
+		recreate[day.Format("2006-01-02")] = true
+	}
+
+	entryStart, entryEnd := opts.entryTimes()
+
+	// Check all days for existing entries up front, several at a time, so
+	// planning only has to look at the results
+	existing, existingErrs := fetchDayEntries(api, opts.Days, opts.ReadConcurrency)
+
+	// Compare with existing entries as Clockify shows them, rounded
+	rounding, err := api.Rounding()
+	if err != nil {
+		return summary, fmt.Errorf("error getting workspace rounding: %v", err)
+	}
+	for _, entries := range existing {
+		for i := range entries {
+			entries[i] = rounding.Entry(entries[i])
+		}
+	}
+
+	// Days before the lock date can't be changed, so they aren't tried
+	lockDate, err := api.LockDate()
+	if err != nil {
+		return summary, fmt.Errorf("error getting workspace lock date: %v", err)
+	}
+
+	// With a target, days are only planned until the time logged on the
+	// days plus the planned entries reaches it
+	target := time.Duration(opts.TargetHours * float64(time.Hour))
+	var alreadyLogged, plannedTime time.Duration
+	for _, entries := range existing {
+		alreadyLogged += loggedTime(entries)
+	}
+
+	// With weekly hours, the last day of each week makes up the rest of
+	// the week's total
+	weekly := time.Duration(opts.WeeklyHours * float64(time.Hour))
+	lastOfWeek := make(map[string]int)
+	weekTime := make(map[string]time.Duration)
+	settled := make(map[string]bool)
+	for i, day := range opts.Days {
+		week := WeekStart(day, opts.WeekStart).Format("2006-01-02")
+		lastOfWeek[week] = i
+		weekTime[week] += loggedTime(existing[i])
+	}
+
+	// Plan entries for days that don't have one yet
+	var planned []plannedEntry
+	statuses := make(map[string]string)
+	lastDescription := defaultDescription
+	for i, day := range opts.Days {
+		startTime := atTimeOfDay(day, entryStart)
+		endTime := atTimeOfDay(day, entryEnd)
+		if opts.KeepDuration {
+			endTime = startTime.Add(entryEnd - entryStart)
+		}
+		fraction, partial := opts.DayFractions[day.Format("2006-01-02")]
+		if partial {
+			endTime = startTime.Add(time.Duration(float64(opts.dailyDuration()) * fraction).Round(time.Minute))
+		}
+		if opts.StartJitter > 0 {
+			offset := startJitterOffset(day, opts.StartJitter, opts.JitterSeed)
+			startTime = startTime.Add(offset)
+			endTime = endTime.Add(offset)
+		}
+		if opts.SecondsJitter > 0 {
+			startOffset, endOffset := jitterOffsets(day, opts.SecondsJitter, opts.JitterSeed)
+			startTime = startTime.Add(startOffset)
+			endTime = endTime.Add(endOffset)
+		}
+
+		if !lockDate.IsZero() && startTime.Before(lockDate) {
+			fmt.Fprintf(out, "Skipping %s - before lock date %s\n", day.Format("2006-01-02"), lockDate.Local().Format("2006-01-02"))
+			summary.Locked++
+			continue
+		}
+
+		dayEntries, err := existing[i], existingErrs[i]
+		if err != nil {
+			fmt.Fprintf(out, "Error checking time entry for %s: %v\n", day.Format("2006-01-02"), err)
+			statuses[day.Format("2006-01-02")] = calendarFailed
+			summary.Failed++
+			continue
+		}
+
+		// ClockiFill's own entries on days being recreated are replaced; with
+		// a source field, only those carrying its value are ClockiFill's
+		var replaces []ExistingTimeEntry
+		if recreate[day.Format("2006-01-02")] && markerTagID != "" {
+			var others []ExistingTimeEntry
+			for _, entry := range dayEntries {
+				if entry.HasTag(markerTagID) && api.hasSourceFields(entry) {
+					replaces = append(replaces, entry)
+				} else {
+					others = append(others, entry)
+				}
+			}
+			dayEntries = others
+		}
+
+		reason := existingEntryReason(dayEntries, opts.Project.ID, startTime, endTime, markerTagID, opts.DuplicateOK)
+		if logged := loggedTime(dayEntries); reason == "" && opts.OnlyEmptyDays && logged > 0 {
+			reason = fmt.Sprintf("%s already logged", FormatDuration(logged))
+		}
+		skip := reason != "" && !opts.Append
+		if skip && !(opts.DryRun && opts.NoSkip) {
+			fmt.Fprintf(out, "Skipping %s - %s\n", day.Format("2006-01-02"), reason)
+			statuses[day.Format("2006-01-02")] = calendarExists
+			summary.Skipped++
+			continue
+		}
+
+		if !skip && target > 0 && alreadyLogged+plannedTime >= target {
+			fmt.Fprintf(out, "Skipping %s - target of %s reached\n", day.Format("2006-01-02"), FormatDuration(target))
+			summary.TargetReached++
+			continue
+		}
+
+		description := defaultDescription
+		if dated, ok := opts.DatedDescriptions[day.Format("2006-01-02")]; ok {
+			description = dated
+		} else if len(opts.DescriptionCycle) > 0 {
+			description = opts.DescriptionCycle[i%len(opts.DescriptionCycle)]
+		} else if opts.DescriptionPrompt != nil {
+			// Days that will be skipped aren't asked about
+			description = lastDescription
+			if !skip {
+				description = opts.DescriptionPrompt(day, lastDescription)
+				lastDescription = description
+			}
+		}
+
+		if opts.ExpandEnv {
+			description = os.ExpandEnv(description)
+		}
+
+		if opts.PrefixTaskName && opts.TaskID != "" && opts.TaskName != "" {
+			description = fmt.Sprintf("[%s] %s", opts.TaskName, description)
+		}
+
+		if opts.BreakNote > 0 && !partial {
+			description = fmt.Sprintf("%s (incl. %s unpaid lunch)", description, FormatDuration(opts.BreakNote))
+			if opts.DeductBreak {
+				endTime = endTime.Add(-opts.BreakNote)
+			}
+		}
+
+		if weekly > 0 && !skip {
+			week := WeekStart(day, opts.WeekStart).Format("2006-01-02")
+			if lastOfWeek[week] == i {
+				settled[week] = true
+				length := weekly - weekTime[week]
+				switch {
+				case length <= 0:
+					fmt.Fprintf(out, "Warning: the week of %s already has %s of %s without %s, keeping its usual entry\n",
+						week, FormatDuration(weekTime[week]), FormatDuration(weekly), day.Format("2006-01-02"))
+				case length > maxPaddedDay:
+					fmt.Fprintf(out, "Warning: %s would need %s to make the week of %s %s, keeping its usual entry\n",
+						day.Format("2006-01-02"), FormatDuration(length), week, FormatDuration(weekly))
+				default:
+					endTime = startTime.Add(length)
+				}
+			}
+			weekTime[week] += endTime.Sub(startTime)
+		}
+
+		if opts.DryRun {
+			note := ""
+			if skip {
+				note = " (exists — would skip)"
+			}
+			fmt.Fprintf(out, "Would create %s %s-%s %q%s\n", day.Format("2006-01-02"),
+				startTime.Format("15:04:05"), endTime.Format("15:04:05"), description, note)
+		}
+		if skip {
+			statuses[day.Format("2006-01-02")] = calendarExists
+			summary.Skipped++
+			continue
+		}
+
+		statuses[day.Format("2006-01-02")] = calendarWillFill
+		plannedTime += endTime.Sub(startTime)
+		planned = append(planned, plannedEntry{
+			Day:         day,
+			Start:       startTime,
+			End:         endTime,
+			Description: description,
+			Replaces:    replaces,
+		})
+	}
+
+	// A week whose last day isn't filled has nothing to make up its total
+	if weekly > 0 {
+		for _, week := range slices.Sorted(maps.Keys(lastOfWeek)) {
+			if !settled[week] && weekTime[week] < weekly {
+				fmt.Fprintf(out, "Warning: the week of %s comes to %s of %s, as its last day %s isn't filled\n",
+					week, FormatDuration(weekTime[week]), FormatDuration(weekly), opts.Days[lastOfWeek[week]].Format("2006-01-02"))
+			}
+		}
+	}
+
+	if target > 0 {
+		result := "met"
+		if short := target - alreadyLogged - plannedTime; short > 0 {
+			result = fmt.Sprintf("not met, %s short", FormatDuration(short))
+		}
+		fmt.Fprintf(out, "Target %s: %s already logged + %s planned on %d days - %s\n", FormatDuration(target),
+			FormatDuration(alreadyLogged), FormatDuration(plannedTime), len(planned), result)
+	}
+
+	if opts.PreviewCalendar {
+		printCalendar(out, opts.Days, statuses)
+	}
+	printPlanPreview(out, api, opts.Project, planned, opts.Billable, opts.Color)
+	if opts.Export != nil {
+		opts.Export.add(opts.Project.Name, planned)
+	}
+
+	if opts.DryRun {
+		if opts.DumpRequests {
+			if err := printRequests(out, api, opts, planned); err != nil {
+				return summary, err
+			}
+		}
+		summary.DryRun = true
+		summary.Added = len(planned)
+		summary.AddedTime = plannedTime
+		return summary, nil
+	}
+
+	err = writePlanned(out, api, opts, planned, &summary)
+	return summary, err
+}
+
+// printRequests prints the curl command that would create each planned
+// entry. The requests are only replayable with the tags' IDs, so the tags
+// are set up for them even though no entries are created.
+func printRequests(out io.Writer, api *API, opts FillOptions, planned []plannedEntry) error {
+	if len(planned) == 0 {
+		return nil
+	}
+	tagIDs, err := entryTagIDs(out, api, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "\nRequests:")
+	for _, entry := range planned {
+		fmt.Fprintln(out, api.AddTimeEntryCurl(opts.Project.ID, entry.Start, entry.End, entry.Description, opts.TaskID, tagIDs, opts.Billable))
+	}
+	return nil
+}
+
+// writePlanned creates the planned entries and adds the outcome to summary.
+// It returns ErrReadOnlyKey if the key turned out to be read-only.
+func writePlanned(out io.Writer, api *API, opts FillOptions, planned []plannedEntry, summary *Summary) error {
+	if len(planned) == 0 {
+		return nil
+	}
+	tagIDs, err := entryTagIDs(out, api, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Reverse {
+		slices.Reverse(planned)
+	}
+
+	// Create the entries, several at a time, but report them in plan order.
+	// Writes are started in plan order too, so one at a time they are
+	// created in exactly that order. Once the key turns out to be
+	// read-only, the remaining writes are not attempted
+	results := make([]chan writeResult, len(planned))
+	limit := make(chan struct{}, max(opts.WriteConcurrency, 1))
+	var readOnly atomic.Bool
+	for i, entry := range planned {
+		results[i] = make(chan writeResult, 1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit }()
+			if readOnly.Load() {
+				results[i] <- writeResult{readOnly: true}
+				return
+			}
+			result := writeEntryWithin(api, opts, entry, tagIDs)
+			if result.readOnly {
+				readOnly.Store(true)
+			}
+			results[i] <- result
+		}()
+	}
+	var written []plannedEntry
+	for i, result := range results {
+		result := <-result
+		switch {
+		case result.readOnly:
+			summary.Failed++
+			continue
+		case result.ok:
+			summary.Added++
+			summary.AddedTime += planned[i].End.Sub(planned[i].Start)
+			written = append(written, planned[i])
+		case result.future:
+			summary.FutureRejected++
+		default:
+			summary.Failed++
+		}
+		io.WriteString(out, result.log)
+	}
+	if readOnly.Load() {
+		return ErrReadOnlyKey
+	}
+
+	if opts.VerifyAfter && len(written) > 0 {
+		summary.Unverified = verifyEntries(out, api, opts.Project.ID, written)
+	}
+	return nil
+}
+
+// verifyEntries reads back the day of each written entry and checks an entry
+// with the same project, start and duration is there, to catch entries that
+// were accepted but didn't persist. It returns the number that are missing
+// or differ.
+func verifyEntries(out io.Writer, api *API, projectID string, written []plannedEntry) int {
+	fmt.Fprintf(out, "\nVerifying %d entries...\n", len(written))
+	unverified := 0
+	for _, entry := range written {
+		day := entry.label()
+		dayEntries, err := api.GetDayEntries(entry.Day)
+		if err != nil {
+			fmt.Fprintf(out, "Could not verify %s: %v\n", day, err)
+			unverified++
+			continue
+		}
+
+		want := entry.End.Sub(entry.Start)
+		var found, sameStart *ExistingTimeEntry
+		for i, existing := range dayEntries {
+			if existing.ProjectID != projectID || !existing.TimeInterval.Start.Equal(entry.Start) {
+				continue
+			}
+			sameStart = &dayEntries[i]
+			if existing.TimeInterval.End != nil && existing.TimeInterval.End.Sub(existing.TimeInterval.Start) == want {
+				found = sameStart
+				break
+			}
+		}
+
+		switch {
+		case found != nil:
+			continue
+		case sameStart != nil && sameStart.TimeInterval.End != nil:
+			fmt.Fprintf(out, "Discrepancy on %s: entry lasts %s, expected %s\n", day,
+				FormatDuration(sameStart.TimeInterval.End.Sub(sameStart.TimeInterval.Start)), FormatDuration(want))
+		default:
+			fmt.Fprintf(out, "Discrepancy on %s: created entry not found\n", day)
+		}
+		unverified++
+	}
+
+	if unverified == 0 {
+		fmt.Fprintln(out, "All entries verified")
+	}
+	return unverified
+}
+
+// fetchDayEntries gets the entries on each day, running up to concurrency
+// requests at once. Results are returned in the order of days.
+func fetchDayEntries(api *API, days []time.Time, concurrency int) ([][]ExistingTimeEntry, []error) {
+	entries := make([][]ExistingTimeEntry, len(days))
+	errs := make([]error, len(days))
+
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, max(concurrency, 1))
+	for i, day := range days {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			entries[i], errs[i] = api.GetDayEntries(day)
+		}()
+	}
+	wg.Wait()
+
+	return entries, errs
+}
+
+// writeEntryWithin writes the entry, giving up on it as failed if it takes
+// longer than TimeoutPerDay, e.g. because retries keep piling up. The
+// request in flight is cancelled then, so the write is over when it returns.
+func writeEntryWithin(api *API, opts FillOptions, entry plannedEntry, tagIDs []string) writeResult {
+	ctx := context.Background()
+	if opts.TimeoutPerDay > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.TimeoutPerDay)
+		defer cancel()
+	}
+
+	result := writeEntry(ctx, api, opts, entry, tagIDs)
+	if !result.ok && !result.future && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.log = fmt.Sprintf("Failed to add time entry for %s: gave up after %s (if Clockify received the request before it was cancelled the entry exists; rerun to fill the day if it does not)\n",
+			entry.label(), opts.TimeoutPerDay)
+	}
+	return result
+}
+
+type writeResult struct {
+	log      string
+	ok       bool
+	future   bool
+	readOnly bool
+}
+
+// writeEntry replaces any entries the planned entry replaces and creates it.
+// Progress is returned rather than printed so concurrent writes can be
+// reported in order.
+func writeEntry(ctx context.Context, api *API, opts FillOptions, entry plannedEntry, tagIDs []string) writeResult {
+	var log strings.Builder
+	label := entry.label()
+	if err := deleteEntries(ctx, api, entry.Replaces); err != nil {
+		fmt.Fprintf(&log, "Failed to recreate time entry for %s: %v\n", label, err)
+		return writeResult{log: log.String()}
+	}
+	if len(entry.Replaces) > 0 {
+		fmt.Fprintf(&log, "Deleted %d ClockiFill entries for %s\n", len(entry.Replaces), label)
+	}
+
+	err := api.addTimeEntry(ctx, opts.Project.ID, entry.Start, entry.End, entry.Description, opts.TaskID, tagIDs, opts.Billable)
+	if errors.Is(err, ErrDescriptionRequired) && opts.FallbackDescription != "" {
+		fmt.Fprintf(&log, "Description rejected for %s, retrying with the fallback %q\n", label, opts.FallbackDescription)
+		err = api.addTimeEntry(ctx, opts.Project.ID, entry.Start, entry.End, opts.FallbackDescription, opts.TaskID, tagIDs, opts.Billable)
+	}
+	if err != nil {
+		if errors.Is(err, ErrReadOnlyKey) {
+			return writeResult{readOnly: true}
+		}
+		if errors.Is(err, ErrFutureEntry) {
+			fmt.Fprintf(&log, "Skipping %s - workspace disallows future entries\n", label)
+			return writeResult{log: log.String(), future: true}
+		}
+		if strings.Contains(err.Error(), "EOF") {
+			fmt.Fprintf(&log, "Skipping %s - Unable to verify existing entries\n", label)
+		} else {
+			fmt.Fprintf(&log, "Failed to add time entry for %s: %v\n", label, err)
+		}
+		return writeResult{log: log.String()}
+	}
+
+	fmt.Fprintf(&log, "Added time entry for %s\n", label)
+	return writeResult{log: log.String(), ok: true}
+}
+
+// loggedTime totals the entries' durations; running entries count up to now.
+func loggedTime(entries []ExistingTimeEntry) time.Duration {
+	var total time.Duration
+	for _, entry := range entries {
+		if entry.TimeInterval.End != nil {
+			total += entry.TimeInterval.End.Sub(entry.TimeInterval.Start)
+		} else {
+			total += time.Since(entry.TimeInterval.Start)
+		}
+	}
+	return total
+}
+
+func deleteEntries(ctx context.Context, api *API, entries []ExistingTimeEntry) error {
+	for _, entry := range entries {
+		if err := api.deleteTimeEntry(ctx, entry.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MissingDays returns the days that have no entry on any project.
+func MissingDays(api *API, days []time.Time) ([]time.Time, error) {
+	var missing []time.Time
+	for _, day := range days {
+		entries, err := api.GetDayEntries(day)
+		if err != nil {
+			return nil, fmt.Errorf("error checking time entries for %s: %v", day.Format("2006-01-02"), err)
+		}
+		if len(entries) == 0 {
+			missing = append(missing, day)
+		}
+	}
+	return missing, nil
+}
+
+// existingEntryReason explains why a day with the given entries must not get
+// a new entry from start to end on the project, or returns an empty string if
+// it can be filled. A day is filled at most once by ClockiFill: any entry
+// carrying the marker tag counts as the day's entry, on whatever project,
+// unless duplicateOK is set. Independently of the marker, an entry is never
+// created over an existing entry on the same project.
+func existingEntryReason(entries []ExistingTimeEntry, projectID string, start, end time.Time, markerTagID string, duplicateOK bool) string {
+	for _, entry := range entries {
+		if markerTagID != "" && !duplicateOK && entry.HasTag(markerTagID) {
+			return "ClockiFill entry already exists"
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.ProjectID == projectID && entry.Overlaps(start, end) {
+			return "Time entry already exists"
+		}
+	}
+
+	return ""
+}
+
+// entryTagIDs returns the tag IDs to attach to every created entry: the
+// marker tag, the draft tag and opts.TagIDs, creating the first two if they
+// don't exist yet.
+func entryTagIDs(out io.Writer, api *API, opts FillOptions) ([]string, error) {
+	tagIDs := markerTagIDs(out, api, opts.MarkerTag)
+	if opts.DraftTag != "" {
+		draftTagID, err := api.EnsureTag(opts.DraftTag)
+		if err != nil {
+			return nil, fmt.Errorf("error setting up draft tag %q: %v", opts.DraftTag, err)
+		}
+		tagIDs = append(tagIDs, draftTagID)
+	}
+	return append(tagIDs, opts.TagIDs...), nil
+}
+
+// markerTagIDs returns the tag IDs to attach to created entries: the marker
+// tag's ID, or none if the marker is disabled or can't be set up.
+func markerTagIDs(out io.Writer, api *API, markerTag string) []string {
+	if markerTag == "" {
+		return nil
+	}
+
+	markerTagID, err := api.EnsureTag(markerTag)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: unable to set up marker tag %q, entries will be created without it: %v\n", markerTag, err)
+		return nil
+	}
+
+	return []string{markerTagID}
+}
+
+// jitterOffsets returns pseudo-random start and end offsets in [0, max),
+// rounded to the second. They depend only on the date and seed, so re-running
+// over the same range produces the same times.
+func jitterOffsets(day time.Time, max time.Duration, seed int64) (time.Duration, time.Duration) {
+	hash := fnv.New64a()
+	hash.Write([]byte(day.Format("2006-01-02")))
+	random := rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+
+	offset := func() time.Duration {
+		return time.Duration(random.Int63n(int64(max))).Truncate(time.Second)
+	}
+	return offset(), offset()
+}
+
+// startJitterOffset returns a pseudo-random offset in [-max, max], rounded
+// to the minute, that like jitterOffsets depends only on the date and seed.
+func startJitterOffset(day time.Time, max time.Duration, seed int64) time.Duration {
+	hash := fnv.New64a()
+	hash.Write([]byte("start " + day.Format("2006-01-02")))
+	random := rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+
+	minutes := int64(max / time.Minute)
+	return time.Duration(random.Int63n(2*minutes+1)-minutes) * time.Minute
+}
+
+var currencySymbols = map[string]string{
+	"GBP": "£",
+	"USD": "$",
+	"EUR": "€",
+	"AUD": "A$",
+	"CAD": "C$",
+	"JPY": "¥",
+}
+
+// formatMoney formats an amount in cents with thousands separators, e.g.
+// 118125 GBP becomes "£1,181.25".
+func formatMoney(cents int64, currency string) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	symbol, ok := currencySymbols[strings.ToUpper(currency)]
+	if !ok {
+		symbol = strings.ToUpper(currency) + " "
+	}
+
+	return fmt.Sprintf("%s%s%s.%02d", sign, symbol, grouped.String(), cents%100)
+}
+
+func printPlanPreview(out io.Writer, api *API, project Project, planned []plannedEntry, billable, color bool) {
+	var total time.Duration
+	for _, entry := range planned {
+		total += entry.End.Sub(entry.Start)
+	}
+
+	projectName := project.Name
+	if color {
+		projectName = Tint(projectName, project.Color)
+	}
+	fmt.Fprintf(out, "\nPlan: %d entries, %.2f hours on %s\n", len(planned), total.Hours(), projectName)
+
+	if !billable || len(planned) == 0 {
+		return
+	}
+
+	rate, err := api.GetHourlyRate(project)
+	if err != nil {
+		fmt.Fprintf(out, "Estimated billable: unavailable (%v)\n", err)
+		return
+	}
+	if rate == nil {
+		fmt.Fprintln(out, "Estimated billable: unavailable (no hourly rate set)")
+		return
+	}
+
+	cents := int64(math.Round(total.Hours() * float64(rate.Amount)))
+	fmt.Fprintf(out, "Estimated billable: %s\n", formatMoney(cents, rate.Currency))
+}
@@ -0,0 +1,403 @@
+package clockify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFillCreatesInPlanOrder(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	dates := []string{
+		"2024-06-03", "2024-06-04", "2024-06-05", "2024-06-06", "2024-06-07",
+		"2024-06-10", "2024-06-11", "2024-06-12", "2024-06-13", "2024-06-14",
+	}
+
+	summary, err := Fill(FillOptions{API: api, Days: testDays(t, dates...), Project: testProject, WriteConcurrency: 1})
+	if err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if summary.Added != len(dates) {
+		t.Errorf("added %d entries, want %d", summary.Added, len(dates))
+	}
+	if got := fake.postedDates(); !slices.Equal(got, dates) {
+		t.Errorf("created %v, want %v", got, dates)
+	}
+}
+
+func TestFillOrder(t *testing.T) {
+	dates := []string{"2024-06-27", "2024-06-28", "2024-07-01", "2024-07-02"}
+	for _, test := range []struct {
+		name    string
+		reverse bool
+		want    []string
+	}{
+		{"chronological", false, dates},
+		{"reverse", true, []string{"2024-07-02", "2024-07-01", "2024-06-28", "2024-06-27"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fake, api := newFakeClockify(t)
+			if _, err := Fill(FillOptions{API: api, Days: testDays(t, dates...), Project: testProject, Reverse: test.reverse}); err != nil {
+				t.Fatalf("Fill: %v", err)
+			}
+			if got := fake.postedDates(); !slices.Equal(got, test.want) {
+				t.Errorf("created %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFillTwiceCreatesNothingMore(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	days := testDays(t, "2024-06-10", "2024-06-11", "2024-06-12")
+	opts := FillOptions{API: api, Days: days, Project: testProject, MarkerTag: "ClockiFill"}
+
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("first Fill: %v", err)
+	}
+	created := len(fake.postedBodies())
+	if created != len(days) {
+		t.Fatalf("first run created %d entries, want %d", created, len(days))
+	}
+
+	// The marker tag makes the day count as filled even at other hours
+	opts.StartTime, opts.EndTime = 17*time.Hour, 18*time.Hour
+	summary, err := Fill(opts)
+	if err != nil {
+		t.Fatalf("second Fill: %v", err)
+	}
+	if posts := len(fake.postedBodies()) - created; posts != 0 {
+		t.Errorf("second run created %d entries, want none", posts)
+	}
+	if summary.Added != 0 || summary.Skipped != len(days) {
+		t.Errorf("second run added %d and skipped %d, want 0 and %d", summary.Added, summary.Skipped, len(days))
+	}
+}
+
+func TestFillKeepDurationAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks go forward on the first day and back on the second, both at
+	// 02:00-03:00, within entries from 01:00 to 08:30
+	days := []time.Time{
+		time.Date(2024, 3, 31, 0, 0, 0, 0, berlin),
+		time.Date(2024, 10, 27, 0, 0, 0, 0, berlin),
+	}
+	const daily = 7*time.Hour + 30*time.Minute
+
+	for _, test := range []struct {
+		keepDuration bool
+		want         []time.Duration
+	}{
+		{true, []time.Duration{daily, daily}},
+		{false, []time.Duration{daily - time.Hour, daily + time.Hour}},
+	} {
+		fake, api := newFakeClockify(t)
+		opts := FillOptions{API: api, Days: days, Project: testProject,
+			StartTime: time.Hour, EndTime: time.Hour + daily, KeepDuration: test.keepDuration}
+		if _, err := Fill(opts); err != nil {
+			t.Fatalf("Fill: %v", err)
+		}
+
+		posts := fake.postedBodies()
+		if len(posts) != len(days) {
+			t.Fatalf("created %d entries, want %d", len(posts), len(days))
+		}
+		for i, body := range posts {
+			start, _ := time.Parse(time.RFC3339, body["start"].(string))
+			end, _ := time.Parse(time.RFC3339, body["end"].(string))
+			if got := end.Sub(start); got != test.want[i] {
+				t.Errorf("keep duration %v: entry on %s lasts %s, want %s", test.keepDuration,
+					days[i].Format("2006-01-02"), FormatDuration(got), FormatDuration(test.want[i]))
+			}
+		}
+	}
+}
+
+func TestFillWeeklyHoursFollowsWeekStart(t *testing.T) {
+	days := testDays(t, "2024-06-28", "2024-06-30", "2024-07-01")
+	const daily = 7*time.Hour + 30*time.Minute
+	for _, test := range []struct {
+		weekStart time.Weekday
+		want      []time.Duration
+	}{
+		// Friday | Sunday, Monday
+		{time.Sunday, []time.Duration{10 * time.Hour, daily, 10*time.Hour - daily}},
+		// Friday, Sunday | Monday
+		{time.Monday, []time.Duration{daily, 10*time.Hour - daily, 10 * time.Hour}},
+	} {
+		fake, api := newFakeClockify(t)
+		opts := FillOptions{API: api, Days: days, Project: testProject, WeeklyHours: 10, WeekStart: test.weekStart}
+		if _, err := Fill(opts); err != nil {
+			t.Fatalf("Fill: %v", err)
+		}
+
+		posts := fake.postedBodies()
+		if len(posts) != len(days) {
+			t.Fatalf("created %d entries, want %d", len(posts), len(days))
+		}
+		for i, body := range posts {
+			start, _ := time.Parse(time.RFC3339, body["start"].(string))
+			end, _ := time.Parse(time.RFC3339, body["end"].(string))
+			if got := end.Sub(start); got != test.want[i] {
+				t.Errorf("%s weeks: entry on %s lasts %s, want %s", test.weekStart,
+					days[i].Format("2006-01-02"), FormatDuration(got), FormatDuration(test.want[i]))
+			}
+		}
+	}
+}
+
+func TestFillRejectsTaskOfAnotherProject(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	fake.tasks[testProject.ID] = []Task{{ID: "task1", Name: "Backend"}}
+	fake.tasks["project2"] = []Task{{ID: "task2", Name: "Frontend"}}
+
+	_, err := Fill(FillOptions{API: api, Days: testDays(t, "2024-06-10"), Project: testProject, TaskID: "task2"})
+	if err == nil || !strings.Contains(err.Error(), "task task2 does not belong to project Project") {
+		t.Errorf("got error %v, want task task2 does not belong to project Project", err)
+	}
+	if posts := len(fake.postedBodies()); posts != 0 {
+		t.Errorf("created %d entries, want none", posts)
+	}
+}
+
+func TestFillDayIsOneSpanOfTheDailyLength(t *testing.T) {
+	// Workspace rounding and a deducted lunch break leave one entry per day
+	// lasting exactly the configured length, with nothing to reconcile
+	fake, api := newFakeClockify(t)
+	fake.settings = `{"timeRoundingInReports":true,"round":{"round":"Round to nearest","minutes":"15"}}`
+	days := testDays(t, "2024-06-10", "2024-06-11")
+	opts := FillOptions{API: api, Days: days, Project: testProject,
+		StartTime: 9 * time.Hour, EndTime: 17*time.Hour + 7*time.Minute, BreakNote: 37 * time.Minute, DeductBreak: true}
+
+	summary, err := Fill(opts)
+	if err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	posts := fake.postedBodies()
+	if len(posts) != len(days) {
+		t.Fatalf("created %d entries, want one per day", len(posts))
+	}
+	for _, body := range posts {
+		start, _ := time.Parse(time.RFC3339, body["start"].(string))
+		end, _ := time.Parse(time.RFC3339, body["end"].(string))
+		if got := end.Sub(start); got != 7*time.Hour+30*time.Minute {
+			t.Errorf("entry %s lasts %s, want 7h30m", body["start"], FormatDuration(got))
+		}
+	}
+	if want := 15 * time.Hour; summary.AddedTime != want {
+		t.Errorf("added %s, want %s", FormatDuration(summary.AddedTime), FormatDuration(want))
+	}
+}
+
+func TestFillCountsRetries(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	api.maxRetries = 5
+	previous := retryBackoff
+	retryBackoff = 0
+	t.Cleanup(func() { retryBackoff = previous })
+
+	// The first request times out, then it's rate limited three times
+	var mu sync.Mutex
+	faults := 0
+	setInjectFault(t, func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		faults++
+		switch {
+		case faults == 1:
+			return nil, fmt.Errorf("%s %s: simulated timeout: %w", req.Method, req.URL, os.ErrDeadlineExceeded)
+		case faults <= 4:
+			header := make(http.Header)
+			header.Set("Retry-After", "0")
+			return &http.Response{
+				Status:     "429 Too Many Requests",
+				StatusCode: http.StatusTooManyRequests,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+		return nil, nil
+	})
+
+	summary, err := Fill(FillOptions{API: api, Days: testDays(t, "2024-06-10"), Project: testProject})
+	if err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if summary.Added != 1 || len(fake.postedBodies()) != 1 {
+		t.Errorf("added %d entries, want 1", summary.Added)
+	}
+	if got, want := api.RetryStats().String(), "Retries: 4 (rate-limit waits: 3, total 0.0s)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFillDumpRequestsMatchesCreatedEntries(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	opts := FillOptions{API: api, Days: testDays(t, "2024-06-10"), Project: testProject,
+		MarkerTag: "ClockiFill", DraftTag: "draft", TagIDs: []string{"extra"}, DryRun: true, DumpRequests: true}
+
+	var out strings.Builder
+	opts.Output = &out
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if posts := len(fake.postedBodies()); posts != 0 {
+		t.Fatalf("dry run created %d entries", posts)
+	}
+	_, quoted, ok := strings.Cut(out.String(), " -d '")
+	if !ok {
+		t.Fatalf("no curl command in %q", out.String())
+	}
+	var dumped map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(quoted), "'"))), &dumped); err != nil {
+		t.Fatalf("curl body: %v", err)
+	}
+
+	opts.DryRun, opts.DumpRequests, opts.Output = false, false, nil
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	posts := fake.postedBodies()
+	if len(posts) != 1 {
+		t.Fatalf("created %d entries, want 1", len(posts))
+	}
+	if !reflect.DeepEqual(dumped, posts[0]) {
+		t.Errorf("dumped request %v, but created %v", dumped, posts[0])
+	}
+	if tags, _ := posts[0]["tagIds"].([]interface{}); len(tags) != 3 {
+		t.Errorf("created entry has tags %v, want the marker, draft and extra tags", posts[0]["tagIds"])
+	}
+}
+
+func TestFillTargetReachedIsNotSkipped(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	days := testDays(t, "2024-06-10", "2024-06-11", "2024-06-12")
+
+	summary, err := Fill(FillOptions{API: api, Days: days, Project: testProject, TargetHours: 15})
+	if err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if posts := len(fake.postedBodies()); posts != 2 {
+		t.Errorf("created %d entries, want 2", posts)
+	}
+	if summary.Skipped != 0 || summary.TargetReached != 1 {
+		t.Errorf("skipped %d and target reached %d, want 0 and 1", summary.Skipped, summary.TargetReached)
+	}
+	if got, want := summary.String(), "Added 2 entries, Skipped 0 existing entries, Not needed 1 (target reached)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFillWeeklyHoursWarnsWhenLastDayIsSkipped(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	fake.addEntry(testProject.ID, at(t, "2024-06-12", "09:00:00"), at(t, "2024-06-12", "16:30:00"))
+
+	var out strings.Builder
+	opts := FillOptions{API: api, Days: testDays(t, "2024-06-10", "2024-06-11", "2024-06-12"), Project: testProject,
+		WeeklyHours: 30, WeekStart: time.Monday, Output: &out}
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if want := "Warning: the week of 2024-06-10 comes to 22h30m of 30h, as its last day 2024-06-12 isn't filled"; !strings.Contains(out.String(), want) {
+		t.Errorf("output %q doesn't warn %q", out.String(), want)
+	}
+}
+
+func TestFillExactTimesStartsAtMidnight(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	opts := FillOptions{API: api, Days: testDays(t, "2024-06-10"), Project: testProject,
+		StartTime: 0, EndTime: 7*time.Hour + 30*time.Minute, ExactTimes: true}
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	posts := fake.postedBodies()
+	if len(posts) != 1 || posts[0]["start"] != "2024-06-10T00:00:00Z" || posts[0]["end"] != "2024-06-10T07:30:00Z" {
+		t.Errorf("created %v, want 00:00 to 07:30", posts)
+	}
+
+	// Zero is midnight for the end too, so the entry would end before it
+	// starts
+	opts.StartTime, opts.EndTime = 22*time.Hour, 0
+	if _, err := Fill(opts); err == nil || !strings.Contains(err.Error(), "got 22:00 to 00:00") {
+		t.Errorf("got error %v, want the times rejected", err)
+	}
+}
+
+func TestFillTimeoutPerDayCancelsTheWrite(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	api.maxRetries = 2
+
+	// Creating an entry hangs until the request is cancelled
+	var mu sync.Mutex
+	inFlight, maxInFlight, cancelled := 0, 0, 0
+	setInjectFault(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method != "POST" || !strings.HasSuffix(req.URL.Path, "/time-entries") {
+			return nil, nil
+		}
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		<-req.Context().Done()
+		mu.Lock()
+		inFlight--
+		cancelled++
+		mu.Unlock()
+		return nil, req.Context().Err()
+	})
+
+	opts := FillOptions{API: api, Days: testDays(t, "2024-06-10", "2024-06-11"), Project: testProject,
+		TimeoutPerDay: 50 * time.Millisecond, WriteConcurrency: 1}
+	summary, err := Fill(opts)
+	if err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+	if summary.Failed != 2 || len(fake.postedBodies()) != 0 {
+		t.Errorf("got %d failed and %d created, want 2 and 0", summary.Failed, len(fake.postedBodies()))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if cancelled != 2 || maxInFlight != 1 {
+		t.Errorf("got %d writes cancelled with at most %d at once, want 2 and 1", cancelled, maxInFlight)
+	}
+}
+
+func TestFillRecreateReplacesOnlyEntriesWithTheSourceField(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	fake.tags = []Tag{{ID: "marker", Name: "ClockiFill"}}
+	fake.fields = []CustomField{{ID: "source", Name: "Source", Type: "TXT"}}
+	fake.addEntry(testProject.ID, at(t, "2024-06-10", "09:00:00"), at(t, "2024-06-10", "16:30:00"), "marker")
+	fake.addEntry(testProject.ID, at(t, "2024-06-10", "17:00:00"), at(t, "2024-06-10", "18:00:00"), "marker")
+	fake.entries[0].CustomFieldValues = []CustomFieldValue{{CustomFieldID: "source", Value: "ClockiFill"}}
+	fake.entries[1].CustomFieldValues = []CustomFieldValue{{CustomFieldID: "source", Value: "Import"}}
+	own, other := fake.entries[0].ID, fake.entries[1].ID
+
+	if err := api.SetSourceField("source", "clockifill"); err != nil {
+		t.Fatalf("SetSourceField: %v", err)
+	}
+	opts := FillOptions{API: api, Days: testDays(t, "2024-06-10"), Project: testProject, MarkerTag: "ClockiFill",
+		RecreateDates: testDays(t, "2024-06-10"), DuplicateOK: true}
+	if _, err := Fill(opts); err != nil {
+		t.Fatalf("Fill: %v", err)
+	}
+
+	var ids []string
+	for _, entry := range fake.entries {
+		ids = append(ids, entry.ID)
+	}
+	if slices.Contains(ids, own) || !slices.Contains(ids, other) || len(fake.postedBodies()) != 1 {
+		t.Errorf("got entries %v after %d created, want %s replaced and %s kept", ids, len(fake.postedBodies()), own, other)
+	}
+}
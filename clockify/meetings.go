@@ -0,0 +1,86 @@
+package clockify
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Meeting is a calendar event to be logged as an entry of its own.
+type Meeting struct {
+	Title      string
+	Start, End time.Time
+}
+
+// FillMeetings creates an entry on opts.Project for each meeting, described
+// by its title, instead of filling daily blocks. Meetings overlapping an
+// entry already on the project are skipped. The entries are written like
+// Fill's, so the tags, lock date, write and dry-run options apply to them
+// too.
+func FillMeetings(opts FillOptions, meetings []Meeting) (Summary, error) {
+	summary := Summary{DryRun: opts.DryRun}
+	if opts.API == nil {
+		return summary, fmt.Errorf("no API client")
+	}
+	if opts.Project.ID == "" {
+		return summary, fmt.Errorf("no project selected")
+	}
+	api := opts.API
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	lockDate, err := api.LockDate()
+	if err != nil {
+		return summary, fmt.Errorf("error getting workspace lock date: %v", err)
+	}
+
+	var planned []plannedEntry
+	for _, meeting := range meetings {
+		span := fmt.Sprintf("%s %s-%s", meeting.Start.Format("2006-01-02"), meeting.Start.Format("15:04"), meeting.End.Format("15:04"))
+
+		if !lockDate.IsZero() && meeting.Start.Before(lockDate) {
+			fmt.Fprintf(out, "Skipping %s %q - before lock date %s\n", span, meeting.Title, lockDate.Local().Format("2006-01-02"))
+			summary.Locked++
+			continue
+		}
+
+		hasEntry, err := api.HasTimeEntry(opts.Project.ID, meeting.Start, meeting.End)
+		if err != nil {
+			fmt.Fprintf(out, "Error checking time entry for %s: %v\n", span, err)
+			summary.Failed++
+			continue
+		}
+		if hasEntry {
+			fmt.Fprintf(out, "Skipping %s %q - Time entry already exists\n", span, meeting.Title)
+			summary.Skipped++
+			continue
+		}
+
+		if opts.DryRun {
+			fmt.Fprintf(out, "Would add time entry for %s %q\n", span, meeting.Title)
+			summary.Added++
+			summary.AddedTime += meeting.End.Sub(meeting.Start)
+		}
+		planned = append(planned, plannedEntry{
+			Day:         startOfDay(meeting.Start),
+			Start:       meeting.Start,
+			End:         meeting.End,
+			Description: meeting.Title,
+			Label:       fmt.Sprintf("%s %q", span, meeting.Title),
+		})
+	}
+
+	if opts.DryRun {
+		if opts.DumpRequests {
+			if err := printRequests(out, api, opts, planned); err != nil {
+				return summary, err
+			}
+		}
+		return summary, nil
+	}
+
+	err = writePlanned(out, api, opts, planned, &summary)
+	return summary, err
+}
@@ -0,0 +1,63 @@
+package clockify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFillMeetingsTagsDrafts(t *testing.T) {
+	fake, api := newFakeClockify(t)
+	meetings := []Meeting{
+		{Title: "Acme sync", Start: at(t, "2024-06-03", "10:00:00"), End: at(t, "2024-06-03", "11:00:00")},
+		{Title: "Retro", Start: at(t, "2024-06-04", "14:00:00"), End: at(t, "2024-06-04", "15:00:00")},
+	}
+
+	opts := FillOptions{API: api, Project: testProject, MarkerTag: "ClockiFill", DraftTag: "draft"}
+	summary, err := FillMeetings(opts, meetings)
+	if err != nil {
+		t.Fatalf("FillMeetings: %v", err)
+	}
+	if summary.Added != 2 {
+		t.Fatalf("added %d meetings, want 2", summary.Added)
+	}
+
+	for _, body := range fake.postedBodies() {
+		tagIDs, _ := body["tagIds"].([]interface{})
+		if len(tagIDs) != 2 {
+			t.Errorf("meeting %v has tags %v, want the marker and draft tags", body["description"], tagIDs)
+		}
+	}
+}
+
+func TestFillMeetingsStopsOnReadOnlyKey(t *testing.T) {
+	_, api := newFakeClockify(t)
+	posts := 0
+	setInjectFault(t, func(req *http.Request) (*http.Response, error) {
+		if req.Method != "POST" || !strings.HasSuffix(req.URL.Path, "/time-entries") {
+			return nil, nil
+		}
+		posts++
+		return &http.Response{
+			Status:     "403 Forbidden",
+			StatusCode: http.StatusForbidden,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+	var meetings []Meeting
+	for _, date := range []string{"2024-06-03", "2024-06-04", "2024-06-05"} {
+		meetings = append(meetings, Meeting{Title: "Standup", Start: at(t, date, "09:00:00"), End: at(t, date, "09:15:00")})
+	}
+
+	summary, err := FillMeetings(FillOptions{API: api, Project: testProject}, meetings)
+	if !errors.Is(err, ErrReadOnlyKey) {
+		t.Fatalf("got error %v, want ErrReadOnlyKey", err)
+	}
+	if posts != 1 || summary.Failed != 3 {
+		t.Errorf("got %d create requests and %d failed, want 1 and 3", posts, summary.Failed)
+	}
+}
@@ -0,0 +1,82 @@
+package clockify
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FindAdjacent groups the entries carrying the marker tag that follow each
+// other on the same day, project, task and billable setting with a gap of
+// less than maxGap. Only groups of two or more are returned, each in start
+// order.
+func FindAdjacent(entries []ExistingTimeEntry, markerTagID string, maxGap time.Duration) [][]ExistingTimeEntry {
+	type groupKey struct {
+		day, project, task string
+		billable           bool
+	}
+	byKey := make(map[groupKey][]ExistingTimeEntry)
+	for _, entry := range entries {
+		if entry.TimeInterval.End == nil || !entry.HasTag(markerTagID) {
+			continue
+		}
+		key := groupKey{entry.TimeInterval.Start.Local().Format("2006-01-02"), entry.ProjectID, entry.TaskID, entry.Billable}
+		byKey[key] = append(byKey[key], entry)
+	}
+
+	var groups [][]ExistingTimeEntry
+	for _, sameKey := range byKey {
+		sort.Slice(sameKey, func(i, j int) bool { return sameKey[i].TimeInterval.Start.Before(sameKey[j].TimeInterval.Start) })
+		group := sameKey[:1]
+		end := *sameKey[0].TimeInterval.End
+		for _, entry := range sameKey[1:] {
+			if entry.TimeInterval.Start.Sub(end) < maxGap {
+				group = append(group, entry)
+			} else {
+				if len(group) > 1 {
+					groups = append(groups, group)
+				}
+				group = []ExistingTimeEntry{entry}
+			}
+			if entry.TimeInterval.End.After(end) || len(group) == 1 {
+				end = *entry.TimeInterval.End
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0].TimeInterval.Start.Before(groups[j][0].TimeInterval.Start) })
+	return groups
+}
+
+// MergedSpan returns the start and end of the single entry a group is merged
+// into.
+func MergedSpan(group []ExistingTimeEntry) (time.Time, time.Time) {
+	start, end := group[0].TimeInterval.Start, *group[0].TimeInterval.End
+	for _, entry := range group[1:] {
+		if entry.TimeInterval.End.After(end) {
+			end = *entry.TimeInterval.End
+		}
+	}
+	return start, end
+}
+
+// MergeEntries replaces a group from FindAdjacent with one entry spanning it,
+// keeping the first entry's description, task, tags and billable setting.
+// The new entry is created before the fragments are deleted, so a failure
+// can leave duplicates to tidy up but never loses time.
+func MergeEntries(api *API, group []ExistingTimeEntry) error {
+	first := group[0]
+	start, end := MergedSpan(group)
+	if err := api.AddTimeEntry(first.ProjectID, start, end, first.Description, first.TaskID, first.TagIDs, first.Billable); err != nil {
+		return fmt.Errorf("error creating merged entry: %v", err)
+	}
+	for _, entry := range group {
+		if err := api.DeleteTimeEntry(entry.ID); err != nil {
+			return fmt.Errorf("merged entry created, but deleting fragment %s failed: %v", entry.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package clockify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateProbe is what ProbeRateLimit observed.
+type RateProbe struct {
+	// Succeeded counts the requests answered before any rate limit, over
+	// Elapsed.
+	Succeeded int
+	Elapsed   time.Duration
+	// Limited means Clockify answered 429 Too Many Requests, after which
+	// the probe stopped.
+	Limited    bool
+	RetryAfter string
+	// Headers holds the rate-limit related response headers seen, such as
+	// X-RateLimit-Limit.
+	Headers map[string]string
+}
+
+// PerSecond returns the observed rate of successful requests.
+func (p RateProbe) PerSecond() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Succeeded) / p.Elapsed.Seconds()
+}
+
+// ProbeRateLimit sends up to requests cheap read-only requests (the user
+// lookup), concurrency at a time, without retries, and stops as soon as
+// one is rate limited. Failures other than 429 end the probe with an error.
+func (api *API) ProbeRateLimit(requests, concurrency int) (RateProbe, error) {
+	probe := RateProbe{Headers: make(map[string]string)}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	sent := 0
+	// next hands out the remaining requests until the probe has to stop
+	next := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if probe.Limited || firstErr != nil || sent >= requests {
+			return false
+		}
+		sent++
+		return true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				resp, err := api.sendRequest(context.Background(), "GET", "/user", nil)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				resp.Body.Close()
+
+				mu.Lock()
+				for name, values := range resp.Header {
+					if strings.Contains(strings.ToLower(name), "ratelimit") || strings.Contains(strings.ToLower(name), "rate-limit") {
+						probe.Headers[name] = strings.Join(values, ", ")
+					}
+				}
+				switch {
+				case resp.StatusCode == http.StatusTooManyRequests:
+					if !probe.Limited {
+						probe.Limited = true
+						probe.Elapsed = time.Since(start)
+						probe.RetryAfter = resp.Header.Get("Retry-After")
+					}
+				case resp.StatusCode != http.StatusOK:
+					if firstErr == nil {
+						firstErr = fmt.Errorf("unexpected status %s", resp.Status)
+					}
+				case !probe.Limited:
+					probe.Succeeded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !probe.Limited {
+		probe.Elapsed = time.Since(start)
+	}
+	return probe, firstErr
+}
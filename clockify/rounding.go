@@ -0,0 +1,64 @@
+package clockify
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Rounding is a workspace's time rounding setting. Clockify rounds the
+// duration of each entry, so an entry shows up as lasting longer or shorter
+// than its recorded end.
+type Rounding struct {
+	// Mode is "Round to nearest", "Round up" or "Round down"; empty means
+	// no rounding.
+	Mode    string
+	Minutes int
+}
+
+// Duration rounds a duration the way the workspace does.
+func (r Rounding) Duration(d time.Duration) time.Duration {
+	if r.Mode == "" || r.Minutes <= 0 {
+		return d
+	}
+
+	step := time.Duration(r.Minutes) * time.Minute
+	down := d.Truncate(step)
+	switch r.Mode {
+	case "Round up":
+		if down < d {
+			return down + step
+		}
+		return down
+	case "Round down":
+		return down
+	default:
+		return d.Round(step)
+	}
+}
+
+// Entry returns the entry with its end moved so it lasts its rounded
+// duration, which is how Clockify presents it. Running entries are returned
+// unchanged.
+func (r Rounding) Entry(entry ExistingTimeEntry) ExistingTimeEntry {
+	if entry.TimeInterval.End == nil {
+		return entry
+	}
+	end := entry.TimeInterval.Start.Add(r.Duration(entry.TimeInterval.End.Sub(entry.TimeInterval.Start)))
+	entry.TimeInterval.End = &end
+	return entry
+}
+
+// Rounding returns the workspace's time rounding setting. Workspaces that
+// don't round return a zero Rounding.
+func (api *API) Rounding() (Rounding, error) {
+	settings, err := api.workspaceSettings()
+	if err != nil || !settings.TimeRoundingInReports {
+		return Rounding{}, err
+	}
+	minutes, err := strconv.Atoi(settings.Round.Minutes)
+	if err != nil {
+		return Rounding{}, fmt.Errorf("unexpected rounding minutes %q", settings.Round.Minutes)
+	}
+	return Rounding{Mode: settings.Round.Round, Minutes: minutes}, nil
+}
@@ -0,0 +1,194 @@
+package clockify
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// FindProject picks the project matching name, ignoring case. An exact name
+// match wins; otherwise the name must be a substring of exactly one project.
+// When several projects match, the error lists them so the user can tell
+// them apart.
+func FindProject(projects []Project, name string) (Project, error) {
+	var exact, partial []Project
+	for _, project := range projects {
+		switch {
+		case strings.EqualFold(project.Name, name):
+			exact = append(exact, project)
+		case strings.Contains(strings.ToLower(project.Name), strings.ToLower(name)):
+			partial = append(partial, project)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("no project matches %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		labels := ProjectLabels(matches)
+		return Project{}, fmt.Errorf("ambiguous project name %q matches %d projects: %s",
+			name, len(matches), strings.Join(labels, ", "))
+	}
+}
+
+// FindProjectByRegex picks the only project whose name matches pattern. When
+// none or several match, the error says so and lists the matches.
+func FindProjectByRegex(projects []Project, pattern *regexp.Regexp) (Project, error) {
+	var matches []Project
+	for _, project := range projects {
+		if pattern.MatchString(project.Name) {
+			matches = append(matches, project)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Project{}, fmt.Errorf("no project matches /%s/", pattern)
+	case 1:
+		return matches[0], nil
+	default:
+		return Project{}, fmt.Errorf("/%s/ matches %d projects: %s",
+			pattern, len(matches), strings.Join(ProjectLabels(matches), ", "))
+	}
+}
+
+// FilterByClient returns the projects of the named client, ignoring case.
+// The error lists the clients there are if none matches.
+func FilterByClient(projects []Project, client string) ([]Project, error) {
+	var matches []Project
+	clients := make(map[string]bool)
+	for _, project := range projects {
+		if strings.EqualFold(project.ClientName, client) {
+			matches = append(matches, project)
+		}
+		if project.ClientName != "" {
+			clients[project.ClientName] = true
+		}
+	}
+	if len(matches) > 0 {
+		return matches, nil
+	}
+
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("no projects for client %q (clients: %s)", client, strings.Join(names, ", "))
+}
+
+// SortByClient orders projects by client name, then project name, with
+// projects without a client last.
+func SortByClient(projects []Project) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		if (a.ClientName == "") != (b.ClientName == "") {
+			return b.ClientName == ""
+		}
+		if !strings.EqualFold(a.ClientName, b.ClientName) {
+			return strings.ToLower(a.ClientName) < strings.ToLower(b.ClientName)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
+
+// ActiveTasks returns the tasks that aren't marked as done.
+func ActiveTasks(tasks []Task) []Task {
+	var active []Task
+	for _, task := range tasks {
+		if task.Status != "DONE" {
+			active = append(active, task)
+		}
+	}
+	return active
+}
+
+// FindTaskByID returns the task with the given ID among the project's tasks.
+// Clockify rejects entries whose task belongs to another project with an
+// unhelpful error, so this is checked before anything is created.
+func FindTaskByID(tasks []Task, taskID string, project Project) (Task, error) {
+	for _, task := range tasks {
+		if task.ID == taskID {
+			return task, nil
+		}
+	}
+	return Task{}, fmt.Errorf("task %s does not belong to project %s", taskID, project.Name)
+}
+
+// FindTaskByName picks the project's task matching name the way FindProject
+// picks projects: ignoring case, an exact match wins, otherwise the name must
+// be a substring of exactly one task.
+func FindTaskByName(tasks []Task, name string, project Project) (Task, error) {
+	var exact, partial []Task
+	for _, task := range tasks {
+		switch {
+		case strings.EqualFold(task.Name, name):
+			exact = append(exact, task)
+		case strings.Contains(strings.ToLower(task.Name), strings.ToLower(name)):
+			partial = append(partial, task)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+
+	switch len(matches) {
+	case 0:
+		return Task{}, fmt.Errorf("project %s has no task matching %q", project.Name, name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, task := range matches {
+			names[i] = task.Name
+		}
+		return Task{}, fmt.Errorf("ambiguous task name %q matches %d tasks of project %s: %s",
+			name, len(matches), project.Name, strings.Join(names, ", "))
+	}
+}
+
+// ProjectLabels returns a display name for each project. Projects that share
+// a name are told apart by their client or, failing that, by the end of
+// their ID.
+func ProjectLabels(projects []Project) []string {
+	counts := make(map[string]int)
+	for _, project := range projects {
+		counts[strings.ToLower(project.Name)]++
+	}
+
+	clientCounts := make(map[string]int)
+	for _, project := range projects {
+		clientCounts[strings.ToLower(project.Name+"\x00"+project.ClientName)]++
+	}
+
+	labels := make([]string, len(projects))
+	for i, project := range projects {
+		labels[i] = project.Name
+		if counts[strings.ToLower(project.Name)] < 2 {
+			continue
+		}
+		if project.ClientName != "" && clientCounts[strings.ToLower(project.Name+"\x00"+project.ClientName)] < 2 {
+			labels[i] = fmt.Sprintf("%s (%s)", project.Name, project.ClientName)
+		} else {
+			labels[i] = fmt.Sprintf("%s (…%s)", project.Name, idSuffix(project.ID))
+		}
+	}
+	return labels
+}
+
+func idSuffix(id string) string {
+	if len(id) > 6 {
+		return id[len(id)-6:]
+	}
+	return id
+}
@@ -0,0 +1,81 @@
+package clockify
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeOffBaseURL is the host of Clockify's time off API.
+const timeOffBaseURL = "https://pto.api.clockify.me/v1"
+
+// timeOffPageSize is how many time off requests are fetched per page.
+const timeOffPageSize = 50
+
+// TimeOffRequest is a request for time off, such as a vacation.
+type TimeOffRequest struct {
+	ID         string `json:"id"`
+	PolicyName string `json:"policyName"`
+	Status     struct {
+		StatusType string `json:"statusType"`
+	} `json:"status"`
+	TimeOffPeriod struct {
+		Period struct {
+			Start time.Time `json:"start"`
+			End   time.Time `json:"end"`
+		} `json:"period"`
+		HalfDay bool `json:"halfDay"`
+	} `json:"timeOffPeriod"`
+}
+
+// ApprovedTimeOff returns the dates (YYYY-MM-DD) between start and end
+// covered by the user's approved time off, mapped to the reason, e.g.
+// "approved time off (Vacation)". Half days count as whole days.
+func (api *API) ApprovedTimeOff(start, end time.Time) (map[string]string, error) {
+	daysOff := make(map[string]string)
+	for page := 1; ; page++ {
+		body := map[string]interface{}{
+			"start":    start.UTC().Format(time.RFC3339),
+			"end":      end.UTC().Format(time.RFC3339),
+			"statuses": []string{"APPROVED"},
+			"users":    []string{api.userID},
+			"page":     page,
+			"pageSize": timeOffPageSize,
+		}
+		resp, err := api.makeRequest("POST", fmt.Sprintf("%s/workspaces/%s/requests", timeOffBaseURL, api.workspaceID), body)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Requests []TimeOffRequest `json:"requests"`
+		}
+		err = decodeResponse(resp, &result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, request := range result.Requests {
+			if request.Status.StatusType != "APPROVED" {
+				continue
+			}
+			reason := "approved time off"
+			if request.PolicyName != "" {
+				reason = fmt.Sprintf("approved time off (%s)", request.PolicyName)
+			}
+			period := request.TimeOffPeriod.Period
+			last := period.End.Local()
+			for day := startOfDay(period.Start.Local()); !day.After(last); day = day.AddDate(0, 0, 1) {
+				if day.Equal(last) && !period.End.Equal(period.Start) {
+					// A period ending at midnight doesn't cover that day
+					break
+				}
+				daysOff[day.Format("2006-01-02")] = reason
+			}
+		}
+
+		if len(result.Requests) < timeOffPageSize {
+			return daysOff, nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package clockify
+
+import "time"
+
+type Workspace struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type HourlyRate struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type Membership struct {
+	TargetID       string      `json:"targetId"`
+	MembershipType string      `json:"membershipType"`
+	HourlyRate     *HourlyRate `json:"hourlyRate"`
+}
+
+type Project struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	ClientID   string      `json:"clientId"`
+	ClientName string      `json:"clientName"`
+	HourlyRate *HourlyRate `json:"hourlyRate"`
+	Color      string      `json:"color"`
+}
+
+type Task struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	// Status is ACTIVE or DONE.
+	Status string `json:"status"`
+}
+
+type WorkspaceUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TimeEntry struct {
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"projectId"`
+	TaskID      string   `json:"taskId,omitempty"`
+	TagIDs      []string `json:"tagIds,omitempty"`
+	Billable    bool     `json:"billable"`
+
+	CustomFields []CustomFieldValue `json:"customFields,omitempty"`
+}
+
+// ExistingTimeEntry is a time entry as returned by Clockify.
+type ExistingTimeEntry struct {
+	ID           string   `json:"id"`
+	Description  string   `json:"description"`
+	ProjectID    string   `json:"projectId"`
+	TaskID       string   `json:"taskId"`
+	TagIDs       []string `json:"tagIds"`
+	Billable     bool     `json:"billable"`
+	TimeInterval struct {
+		Start time.Time  `json:"start"`
+		End   *time.Time `json:"end"`
+	} `json:"timeInterval"`
+	CustomFieldValues []CustomFieldValue `json:"customFieldValues"`
+}
+
+// Overlaps reports whether the entry overlaps the span from start to end.
+// A running entry (no end yet) is treated as lasting until now.
+func (e ExistingTimeEntry) Overlaps(start, end time.Time) bool {
+	entryEnd := time.Now()
+	if e.TimeInterval.End != nil {
+		entryEnd = *e.TimeInterval.End
+	}
+	return e.TimeInterval.Start.Before(end) && entryEnd.After(start)
+}
+
+// HasTag reports whether the entry carries the given tag. With the marker tag
+// this identifies entries created by ClockiFill, so anything that modifies or
+// deletes entries can leave manually-entered time alone.
+func (e ExistingTimeEntry) HasTag(tagID string) bool {
+	for _, id := range e.TagIDs {
+		if id == tagID {
+			return true
+		}
+	}
+	return false
+}
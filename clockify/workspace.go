@@ -0,0 +1,70 @@
+package clockify
+
+import (
+	"fmt"
+	"time"
+)
+
+// workspaceSettings are the workspace settings ClockiFill takes into
+// account.
+type workspaceSettings struct {
+	TimeRoundingInReports bool `json:"timeRoundingInReports"`
+	Round                 struct {
+		Round   string `json:"round"`
+		Minutes string `json:"minutes"`
+	} `json:"round"`
+	// LockTimeEntries is the date before which entries are locked, or
+	// empty if they aren't.
+	LockTimeEntries string `json:"lockTimeEntries"`
+	// DefaultBillableProjects is whether new projects, and so their
+	// entries, are billable by default.
+	DefaultBillableProjects bool `json:"defaultBillableProjects"`
+}
+
+// workspaceSettings fetches the workspace's settings once and then
+// remembers them.
+func (api *API) workspaceSettings() (workspaceSettings, error) {
+	api.settingsOnce.Do(func() {
+		api.settings, api.settingsErr = api.getWorkspaceSettings()
+	})
+	return api.settings, api.settingsErr
+}
+
+func (api *API) getWorkspaceSettings() (workspaceSettings, error) {
+	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s", api.workspaceID), nil)
+	if err != nil {
+		return workspaceSettings{}, err
+	}
+	defer resp.Body.Close()
+
+	var workspace struct {
+		WorkspaceSettings workspaceSettings `json:"workspaceSettings"`
+	}
+	if err := decodeResponse(resp, &workspace); err != nil {
+		return workspaceSettings{}, err
+	}
+	return workspace.WorkspaceSettings, nil
+}
+
+// LockDate returns the moment before which the workspace's entries are
+// locked against changes, or the zero time if nothing is locked.
+func (api *API) LockDate() (time.Time, error) {
+	settings, err := api.workspaceSettings()
+	if err != nil || settings.LockTimeEntries == "" {
+		return time.Time{}, err
+	}
+	if lock, err := time.Parse(time.RFC3339, settings.LockTimeEntries); err == nil {
+		return lock, nil
+	}
+	if lock, err := time.ParseInLocation("2006-01-02", settings.LockTimeEntries, time.Local); err == nil {
+		return lock, nil
+	}
+	return time.Time{}, fmt.Errorf("unexpected lock date %q", settings.LockTimeEntries)
+}
+
+// DefaultBillable returns whether the workspace makes work billable by
+// default.
+func (api *API) DefaultBillable() (bool, error) {
+	settings, err := api.workspaceSettings()
+	return settings.DefaultBillableProjects, err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxCommitDescription is the longest description made from commits, in
+// characters.
+const maxCommitDescription = 200
+
+// commitDescriptions returns a description for each day with commits by
+// the repository's configured user (git config user.email), keyed by
+// YYYY-MM-DD: the day's commit subjects, oldest first, joined with "; " and
+// cut to a sensible length. Days without commits are left out.
+func commitDescriptions(repo string, days []time.Time) (map[string]string, error) {
+	output, err := exec.Command("git", "-C", repo, "config", "user.email").Output()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read user.email of the git repository %s: %v", repo, err)
+	}
+	author := strings.TrimSpace(string(output))
+	if author == "" {
+		return nil, fmt.Errorf("user.email is not set in the git repository %s", repo)
+	}
+
+	descriptions := make(map[string]string)
+	if len(days) == 0 {
+		return descriptions, nil
+	}
+
+	// Commits are grouped by when they were authored; a commit can't be
+	// committed before that, so one log from the first day covers them all.
+	// git's --author is a pattern, so the author is matched here instead
+	first := days[0]
+	output, err = exec.Command("git", "-C", repo, "log", "--all", "--no-merges", "--reverse",
+		"--since="+time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, first.Location()).Format(time.RFC3339),
+		"--format=%ae%x09%aI%x09%s").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading commits of %s: %v", repo, err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, day := range days {
+		wanted[day.Format("2006-01-02")] = true
+	}
+	subjects := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 || !strings.EqualFold(fields[0], author) {
+			continue
+		}
+		authored, subject := fields[1], fields[2]
+		when, err := time.Parse(time.RFC3339, authored)
+		if err != nil {
+			continue
+		}
+		date := when.In(first.Location()).Format("2006-01-02")
+		subject = strings.TrimSpace(subject)
+		if !wanted[date] || subject == "" || seen[date+"\t"+subject] {
+			continue
+		}
+		seen[date+"\t"+subject] = true
+		subjects[date] = append(subjects[date], subject)
+	}
+	for date, daySubjects := range subjects {
+		descriptions[date] = truncateDescription(strings.Join(daySubjects, "; "), maxCommitDescription)
+	}
+	return descriptions, nil
+}
+
+// truncateDescription cuts a description to at most max characters at a
+// word boundary, marking the cut with an ellipsis.
+func truncateDescription(description string, max int) string {
+	runes := []rune(description)
+	if len(runes) <= max {
+		return description
+	}
+	cut := string(runes[:max-1])
+	if space := strings.LastIndex(cut, " "); space > max/2 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ;,") + "…"
+}
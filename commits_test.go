@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCommitDescriptionsMatchesAuthorLiterally(t *testing.T) {
+	repo := t.TempDir()
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git(nil, "init", "-q")
+	git(nil, "config", "user.email", "a+b@example.com")
+	git(nil, "config", "user.name", "Tester")
+
+	// As a pattern, a+b@example.com would match aab@example.com and not
+	// itself
+	for _, commit := range []struct{ email, subject string }{
+		{"a+b@example.com", "Add the parser"},
+		{"aab@example.com", "Someone else's work"},
+		{"A+B@example.com", "Fix the parser"},
+	} {
+		git([]string{"GIT_AUTHOR_EMAIL=" + commit.email, "GIT_AUTHOR_DATE=2024-06-10T10:00:00Z", "GIT_COMMITTER_DATE=2024-06-10T10:00:00Z"},
+			"commit", "-q", "--allow-empty", "-m", commit.subject)
+	}
+
+	descriptions, err := commitDescriptions(repo, []time.Time{time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("commitDescriptions: %v", err)
+	}
+	if got, want := descriptions["2024-06-10"], "Add the parser; Fix the parser"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
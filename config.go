@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+	"gopkg.in/yaml.v3"
+)
+
+// config holds the settings read from the config file, by default
+// ~/.clockifill.yaml:
+//
+//	default_description: Development
+//	default_project: Acme
+//	projects:
+//	  Acme:
+//	    description: Acme support
+//	    billable: true
+//	    task: Backend
+//	    start: "09:00"
+//	    end: "12:30"
+//	    meetings: "(?i)acme"
+type config struct {
+	// DefaultDescription replaces "Standard workday" for every project.
+	DefaultDescription string `yaml:"default_description"`
+	// DefaultProject is filled when no project is given on the command
+	// line, instead of asking.
+	DefaultProject string `yaml:"default_project"`
+
+	Projects map[string]projectConfig `yaml:"projects"`
+}
+
+// projectConfig holds the settings for one project, keyed by project name.
+type projectConfig struct {
+	// Description replaces the default description for this project.
+	Description string `yaml:"description"`
+	// Billable, if set, decides whether the project's entries are billable
+	// instead of asking.
+	Billable *bool `yaml:"billable"`
+	// Task is the name of the task to log against.
+	Task string `yaml:"task"`
+	// Start and End replace 09:00 and 16:30 as HH:MM or e.g. 4:30pm. They
+	// are required when several projects are filled together.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Meetings is a regular expression; calendar meetings whose title
+	// matches it are logged to this project by --meetings-ics.
+	Meetings string `yaml:"meetings"`
+}
+
+// hours returns the project's start and end times of day, or 09:00 and 16:30
+// where they aren't set. A start of 00:00 is midnight, not the default.
+func (p projectConfig) hours() (time.Duration, time.Duration, error) {
+	start, end := clockify.DefaultStartTime, clockify.DefaultEndTime
+	var err error
+	if p.Start != "" {
+		if start, err = parseClock(p.Start); err != nil {
+			return 0, 0, fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if p.End != "" {
+		if end, err = parseClock(p.End); err != nil {
+			return 0, 0, fmt.Errorf("invalid end: %v", err)
+		}
+	}
+	return start, end, nil
+}
+
+// clockLayouts are the accepted times of day: 24-hour, and 12-hour with
+// am or pm, with or without minutes.
+var clockLayouts = []string{"15:04", "3:04pm", "3pm"}
+
+// parseClock parses a time of day such as "09:30" or "4:30pm" into an
+// offset from midnight. An empty value gives zero.
+func parseClock(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	normalized := strings.ToLower(strings.ReplaceAll(value, " ", ""))
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, normalized); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot parse %q: expected a 24-hour time like 16:30 or a 12-hour time like 4:30pm", value)
+}
+
+// checkProjectHours makes sure every project has its own hours configured
+// and that they don't overlap, so projects filled together can't collide.
+func checkProjectHours(cfg config, projects []clockify.Project) error {
+	type span struct {
+		name       string
+		start, end time.Duration
+	}
+	var spans []span
+	var problems []error
+	for _, project := range projects {
+		projectCfg := cfg.project(project.Name)
+		if projectCfg.Start == "" || projectCfg.End == "" {
+			problems = append(problems, fmt.Errorf("project %s needs start and end times in the config file to be filled with other projects", project.Name))
+			continue
+		}
+		start, end, err := projectCfg.hours()
+		if err != nil {
+			problems = append(problems, fmt.Errorf("project %s: %v", project.Name, err))
+			continue
+		}
+		for _, other := range spans {
+			if start < other.end && other.start < end {
+				problems = append(problems, fmt.Errorf("the hours of projects %s and %s overlap", other.name, project.Name))
+			}
+		}
+		spans = append(spans, span{project.Name, start, end})
+	}
+	return errors.Join(problems...)
+}
+
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".clockifill.yaml")
+}
+
+// loadConfig reads the config file at path, or the default one if path is
+// empty. A missing default config file is not an error.
+func loadConfig(path string) (config, error) {
+	var cfg config
+	explicit := path != ""
+	if !explicit {
+		if path = defaultConfigPath(); path == "" {
+			return cfg, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// project returns the settings for the named project, matching the name
+// ignoring case.
+func (c config) project(name string) projectConfig {
+	for projectName, settings := range c.Projects {
+		if strings.EqualFold(projectName, name) {
+			return settings
+		}
+	}
+	return projectConfig{}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectHoursMidnightStart(t *testing.T) {
+	for _, test := range []struct {
+		start, end string
+		wantStart  time.Duration
+		wantEnd    time.Duration
+	}{
+		{"00:00", "07:30", 0, 7*time.Hour + 30*time.Minute},
+		{"12am", "7:30am", 0, 7*time.Hour + 30*time.Minute},
+		{"", "", 9 * time.Hour, 16*time.Hour + 30*time.Minute},
+		{"22:00", "", 22 * time.Hour, 16*time.Hour + 30*time.Minute},
+	} {
+		start, end, err := projectConfig{Start: test.start, End: test.end}.hours()
+		if err != nil {
+			t.Errorf("hours(%q, %q): %v", test.start, test.end, err)
+			continue
+		}
+		if start != test.wantStart || end != test.wantEnd {
+			t.Errorf("hours(%q, %q) = %s, %s, want %s, %s", test.start, test.end, start, end, test.wantStart, test.wantEnd)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"clockifill/clockify"
+)
+
+// runConfigInit asks for the usual settings and writes them to a commented
+// config file, so later runs need fewer flags and prompts.
+func runConfigInit(args []string) {
+	fs := flag.NewFlagSet("config-init", flag.ExitOnError)
+	configPath := fs.String("config", "", "file to write (default: ~/.clockifill.yaml)")
+	force := fs.Bool("force", false, "overwrite the file if it already exists")
+	fs.Parse(args)
+
+	path := *configPath
+	if path == "" {
+		if path = defaultConfigPath(); path == "" {
+			fmt.Println("Error: cannot find your home directory; pass --config")
+			return
+		}
+	}
+	if _, err := os.Stat(path); err == nil && !*force {
+		fmt.Printf("%s already exists; pass --force to overwrite it\n", path)
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(`# ClockiFill config file, read by "clockifill fill". See the "Config file"
+# section of the README for all settings. Flags given on the command line
+# take precedence.
+
+`)
+
+	defaultDescription := promptDefault("Default description for all projects", clockify.DefaultDescription)
+	text.WriteString("# Description used for every project without its own description\n")
+	fmt.Fprintf(&text, "default_description: %s\n\n", yamlString(defaultDescription))
+
+	var projects strings.Builder
+	var names []string
+	for {
+		fmt.Print("\nProject to configure (Enter to finish): ")
+		name := readLine()
+		if name == "" {
+			break
+		}
+		names = append(names, name)
+
+		fmt.Fprintf(&projects, "  %s:\n", yamlString(name))
+		fmt.Print("Description (Enter for the default): ")
+		if description := readLine(); description != "" {
+			fmt.Fprintf(&projects, "    description: %s\n", yamlString(description))
+		}
+		fmt.Print("Billable? (y/n, Enter to be asked each time): ")
+		switch strings.ToLower(readLine()) {
+		case "y", "yes":
+			projects.WriteString("    billable: true\n")
+		case "n", "no":
+			projects.WriteString("    billable: false\n")
+		}
+		fmt.Print("Task name (Enter for none): ")
+		if task := readLine(); task != "" {
+			fmt.Fprintf(&projects, "    task: %s\n", yamlString(task))
+		}
+		if start := promptClock("Start time, e.g. 09:00 or 9am (Enter for 09:00): "); start != "" {
+			fmt.Fprintf(&projects, "    start: %s\n", yamlString(start))
+		}
+		if end := promptClock("End time, e.g. 16:30 or 4:30pm (Enter for 16:30): "); end != "" {
+			fmt.Fprintf(&projects, "    end: %s\n", yamlString(end))
+		}
+	}
+	fmt.Print("\nProject to fill when no --project is given (Enter to choose each time): ")
+	defaultProject := readLine()
+	text.WriteString("# Project filled when no --project is given, instead of choosing from a menu\n")
+	if defaultProject != "" {
+		fmt.Fprintf(&text, "default_project: %s\n\n", yamlString(defaultProject))
+	} else {
+		example := "Project name"
+		if len(names) > 0 {
+			example = names[0]
+		}
+		fmt.Fprintf(&text, "# default_project: %s\n\n", yamlString(example))
+	}
+
+	text.WriteString(`# Settings per project, matched by name ignoring case:
+#   description  default description for the project
+#   billable     true or false, instead of asking
+#   task         name of the task to log against
+#   start, end   working hours as "HH:MM" or "4:30pm", instead of 09:00 to 16:30;
+#                required for each project when filling several together
+`)
+	if projects.Len() == 0 {
+		text.WriteString("projects: {}\n")
+	} else {
+		text.WriteString("projects:\n")
+		text.WriteString(projects.String())
+	}
+
+	if err := os.WriteFile(path, []byte(text.String()), 0o644); err != nil {
+		fmt.Printf("Error writing config file: %v\n", err)
+		return
+	}
+	fmt.Printf("\nWrote %s\n", path)
+}
+
+// promptClock asks for an optional time of day until it is empty or valid.
+func promptClock(question string) string {
+	for {
+		fmt.Print(question)
+		value := readLine()
+		if _, err := parseClock(value); err != nil {
+			fmt.Println(err)
+			exitIfNoInput()
+			continue
+		}
+		return value
+	}
+}
+
+// yamlString quotes a string for YAML; JSON strings are valid YAML scalars.
+func yamlString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"clockifill/clockify"
+)
+
+func runDelete(args []string) {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	markerTag := fs.String("marker-tag", "clockifill", "tag identifying the entries created by ClockiFill; only these are deleted")
+	sourceField := fs.String("source-field", "", "name=value of a custom field, e.g. Source=ClockiFill; delete the entries carrying it instead of those with the marker tag")
+	yes := fs.Bool("yes", false, "delete without asking for confirmation")
+	logFile := fs.String("log-file", "", "also append the output of the run to this file")
+	logMaxSize := fs.Int64("log-max-size", 10, "size in MB after which --log-file is moved to <file>.1 and started afresh")
+	noLock := fs.Bool("no-lock", false, "don't take the lock that stops two runs from changing entries at the same time")
+	rangeOpts := addRangeFlags(fs)
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	if *logFile != "" {
+		stop, err := startLog(*logFile, *logMaxSize<<20)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer stop()
+	}
+
+	rangeStart, rangeEnd, problems := rangeOpts.resolve(time.Now())
+	var sourceFieldName, sourceFieldValue string
+	if *sourceField != "" {
+		var err error
+		if sourceFieldName, sourceFieldValue, err = parseFieldSetting(*sourceField); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --source-field: %v", err))
+		}
+	} else if *markerTag == "" {
+		problems = append(problems, fmt.Errorf("--marker-tag or --source-field is required to tell ClockiFill's entries apart from manual ones"))
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Invalid options:\n%v\n", errors.Join(problems...))
+		return
+	}
+	if emptyRange(rangeStart, rangeEnd) {
+		return
+	}
+
+	if !*noLock {
+		release, err := acquireLock()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer release()
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	// Entries are picked by the source field if given, else the marker tag
+	var isClockiFills func(entry clockify.ExistingTimeEntry) bool
+	if sourceFieldName != "" {
+		field, err := api.FindCustomField(sourceFieldName)
+		if err != nil {
+			fmt.Printf("Error looking up --source-field: %v\n", err)
+			return
+		}
+		isClockiFills = func(entry clockify.ExistingTimeEntry) bool {
+			return entry.HasCustomFieldValue(field.ID, sourceFieldValue)
+		}
+	} else {
+		markerTagID, err := api.FindTag(*markerTag)
+		if err != nil {
+			fmt.Printf("Error looking up marker tag %q: %v\n", *markerTag, err)
+			return
+		}
+		if markerTagID == "" {
+			fmt.Printf("No entries to delete: the %q tag doesn't exist\n", *markerTag)
+			return
+		}
+		isClockiFills = func(entry clockify.ExistingTimeEntry) bool {
+			return entry.HasTag(markerTagID)
+		}
+	}
+
+	entries, err := api.GetTimeEntries("", rangeStart, rangeEnd)
+	if err != nil {
+		fmt.Printf("Error getting time entries: %v\n", err)
+		return
+	}
+
+	var marked []string
+	var dates []string
+	var total time.Duration
+	for _, entry := range entries {
+		if isClockiFills(entry) {
+			marked = append(marked, entry.ID)
+			dates = append(dates, entry.TimeInterval.Start.Local().Format("2006-01-02"))
+			if entry.TimeInterval.End != nil {
+				total += entry.TimeInterval.End.Sub(entry.TimeInterval.Start)
+			}
+		}
+	}
+
+	if len(marked) == 0 {
+		fmt.Println("No ClockiFill entries found in the range")
+		return
+	}
+
+	fmt.Printf("Found %d ClockiFill entries (%s) between %s and %s\n", len(marked), clockify.FormatDuration(total),
+		rangeStart.Format("2006-01-02"), rangeEnd.Format("2006-01-02"))
+
+	// Deleting is destructive, so the count must be typed back rather than
+	// just answering yes
+	if !*yes {
+		fmt.Printf("Type the number of entries (%d) to delete them: ", len(marked))
+		if answer := readLine(); answer != strconv.Itoa(len(marked)) {
+			fmt.Println("Nothing deleted")
+			return
+		}
+	}
+
+	deleted, failed := 0, 0
+	for i, entryID := range marked {
+		if err := api.DeleteTimeEntry(entryID); err != nil {
+			fmt.Printf("[%d/%d] Failed to delete time entry for %s: %v\n", i+1, len(marked), dates[i], err)
+			failed++
+			continue
+		}
+		fmt.Printf("[%d/%d] Deleted time entry for %s\n", i+1, len(marked), dates[i])
+		deleted++
+	}
+
+	fmt.Printf("\nSummary: Deleted %d entries, Failed %d\n", deleted, failed)
+	printRetryStats(api)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// readDescriptionsFile reads a notes file of "YYYY-MM-DD: description" lines
+// into a map from date to description. Lines that don't start with a date
+// are ignored, so the file can hold headings and other notes too.
+func readDescriptionsFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	descriptions := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		date, description, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			continue
+		}
+		if description = strings.TrimSpace(description); description != "" {
+			descriptions[date] = description
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return descriptions, nil
+}
+
+// editDescriptions opens $VISUAL or $EDITOR (vi if neither is set) on a file
+// listing each day with its description, the way git asks for commit
+// messages, and reads the descriptions back once the editor exits.
+func editDescriptions(days []time.Time, defaultDescription string, dated map[string]string) (map[string]string, error) {
+	file, err := os.CreateTemp("", "clockifill-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintln(writer, "# Write each day's description after its date and save the file.")
+	fmt.Fprintln(writer, "# Days left empty or removed get the default description; lines")
+	fmt.Fprintln(writer, "# starting with # are ignored. Days that already have an entry are")
+	fmt.Fprintln(writer, "# skipped whatever is written here.")
+	for i, day := range days {
+		if i > 0 && day.Weekday() < days[i-1].Weekday() {
+			fmt.Fprintln(writer)
+		}
+		description, ok := dated[day.Format("2006-01-02")]
+		if !ok {
+			description = defaultDescription
+		}
+		fmt.Fprintf(writer, "%s: %s\n", day.Format("2006-01-02"), description)
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The editor may come with arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running editor %q: %v", editor, err)
+	}
+
+	return readDescriptionsFile(file.Name())
+}
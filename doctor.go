@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"clockifill/clockify"
+)
+
+// runDoctor checks each piece of setup in turn and reports what's wrong,
+// without changing anything in Clockify.
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	markerTag := fs.String("marker-tag", "clockifill", "marker tag to look for")
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	ok := true
+	check := func(name string, err error) bool {
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", name, err)
+			ok = false
+			return false
+		}
+		fmt.Printf("OK   %s\n", name)
+		return true
+	}
+
+	loaded, err := apiOpts.loadEnv()
+	if check(".env file", err) {
+		if len(loaded) == 0 {
+			fmt.Println("     none found; using the environment")
+		} else {
+			fmt.Printf("     loaded %s\n", strings.Join(loaded, ", "))
+		}
+	}
+
+	var apiKeyErr error
+	if os.Getenv("CLOCKIFY_API_KEY") == "" {
+		apiKeyErr = fmt.Errorf("CLOCKIFY_API_KEY is not set")
+	}
+	if !check("API key", apiKeyErr) {
+		os.Exit(1)
+	}
+
+	api, err := clockify.NewAPI(clockify.Config{
+		APIKey:       os.Getenv("CLOCKIFY_API_KEY"),
+		WorkspaceID:  os.Getenv("CLOCKIFY_WORKSPACE_ID"),
+		ReadTimeout:  *apiOpts.readTimeout,
+		WriteTimeout: *apiOpts.writeTimeout,
+		MaxRetries:   *apiOpts.maxRetries,
+	})
+	if !check("Clockify connection", err) {
+		os.Exit(1)
+	}
+	fmt.Printf("     workspace %s, user %s\n", api.WorkspaceID(), api.UserID())
+
+	if check("Clock", checkClockSkew(api.ClockSkew())) {
+		fmt.Printf("     local clock differs from Clockify's by %s\n", api.ClockSkew())
+	}
+
+	projects, err := api.GetProjects()
+	if check("Projects", err) {
+		fmt.Printf("     %d projects available\n", len(projects))
+	}
+
+	if *markerTag != "" {
+		tagID, err := api.FindTag(*markerTag)
+		if check("Marker tag", err) && tagID == "" {
+			fmt.Printf("     %q doesn't exist yet; it is created on the first fill\n", *markerTag)
+		}
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// dumpConfig prints the settings a fill would use, as YAML with the source
+// of each value in a comment, so layered flags, config file and environment
+// can be untangled. The API key and URLs that can carry secrets are
+// redacted.
+func dumpConfig(fs *flag.FlagSet, apiOpts *apiFlags, cfg config, configPath string, rangeStart, rangeEnd time.Time) {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	flagSource := func(name string) string {
+		if set[name] {
+			return "flag"
+		}
+		return "default"
+	}
+
+	// Variables already in the environment win over .env files
+	envSources := make(map[string]string)
+	for _, name := range []string{"CLOCKIFY_API_KEY", "CLOCKIFY_WORKSPACE_ID"} {
+		if os.Getenv(name) != "" {
+			envSources[name] = "environment"
+		}
+	}
+	loaded, err := apiOpts.loadEnv()
+	if err != nil {
+		fmt.Printf("# %v\n", err)
+	}
+	for _, name := range []string{"CLOCKIFY_API_KEY", "CLOCKIFY_WORKSPACE_ID"} {
+		if envSources[name] == "" && os.Getenv(name) != "" {
+			envSources[name] = strings.Join(loaded, ", ")
+		}
+	}
+
+	fmt.Println("# Effective settings; each value's source is in its comment")
+	fmt.Println("env:")
+	fmt.Printf("  files: %s\n", yamlString(strings.Join(loaded, ", ")))
+	fmt.Printf("  CLOCKIFY_API_KEY: %s  # %s\n", yamlString(redact(os.Getenv("CLOCKIFY_API_KEY"))), sourceOr(envSources["CLOCKIFY_API_KEY"], "not set"))
+	fmt.Printf("  CLOCKIFY_WORKSPACE_ID: %s  # %s\n", yamlString(os.Getenv("CLOCKIFY_WORKSPACE_ID")), sourceOr(envSources["CLOCKIFY_WORKSPACE_ID"], "not set: your first workspace"))
+
+	configSource := "flag"
+	if configPath == "" {
+		configSource, configPath = "default", defaultConfigPath()
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		configSource += ", file not found"
+	}
+	fmt.Printf("config_file: %s  # %s\n", yamlString(configPath), configSource)
+
+	fmt.Println("range:")
+	rangeSource := "default"
+	if set["from"] || set["to"] || set["last-week"] || set["through-yesterday"] {
+		rangeSource = "flag"
+	}
+	fmt.Printf("  from: %s  # %s\n", rangeStart.Format("2006-01-02"), rangeSource)
+	fmt.Printf("  to: %s  # %s\n", rangeEnd.Format("2006-01-02"), rangeSource)
+
+	description, descriptionSource := clockify.DefaultDescription, "default"
+	switch {
+	case set["default-description"]:
+		description, descriptionSource = fs.Lookup("default-description").Value.String(), "flag"
+	case cfg.DefaultDescription != "":
+		description, descriptionSource = cfg.DefaultDescription, "config file"
+	}
+	fmt.Printf("default_description: %s  # %s\n", yamlString(description), descriptionSource)
+
+	if cfg.DefaultProject != "" {
+		fmt.Printf("default_project: %s  # config file\n", yamlString(cfg.DefaultProject))
+	}
+
+	names := make([]string, 0, len(cfg.Projects))
+	for name := range cfg.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		fmt.Println("projects: {}  # none in the config file")
+	} else {
+		fmt.Println("projects:  # config file; flags such as --start-time override them")
+	}
+	for _, name := range names {
+		project := cfg.Projects[name]
+		fmt.Printf("  %s:\n", yamlString(name))
+		if project.Description != "" {
+			fmt.Printf("    description: %s\n", yamlString(project.Description))
+		}
+		if project.Billable != nil {
+			fmt.Printf("    billable: %t\n", *project.Billable)
+		}
+		if project.Task != "" {
+			fmt.Printf("    task: %s\n", yamlString(project.Task))
+		}
+		if project.Start != "" {
+			fmt.Printf("    start: %s\n", yamlString(project.Start))
+		}
+		if project.End != "" {
+			fmt.Printf("    end: %s\n", yamlString(project.End))
+		}
+	}
+
+	fmt.Println("flags:")
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "dump-config" {
+			return
+		}
+		value := f.Value.String()
+		if secretURLFlags[f.Name] {
+			value = redactURL(value)
+		}
+		fmt.Printf("  %s: %s  # %s\n", f.Name, yamlString(value), flagSource(f.Name))
+	})
+}
+
+// secretURLFlags are the flags whose URLs can hold a secret, such as a Slack
+// webhook's path or a private calendar feed's token.
+var secretURLFlags = map[string]bool{
+	"webhook-url":  true,
+	"calendar-ics": true,
+	"meetings-ics": true,
+}
+
+// redactURL hides everything of a URL but its scheme and host. Values that
+// aren't URLs, such as file paths, are returned unchanged.
+func redactURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return value
+	}
+	redacted := parsed.Scheme + "://" + parsed.Host
+	if parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		redacted += "/****"
+	}
+	return redacted
+}
+
+// redact hides all but the end of a secret.
+func redact(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+func sourceOr(source, fallback string) string {
+	if source == "" {
+		return fallback
+	}
+	return source
+}
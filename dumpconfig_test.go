@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDumpConfigRedactsSecrets(t *testing.T) {
+	const (
+		apiKey  = "supersecretkey1234"
+		webhook = "https://hooks.slack.com/services/T000/B000/webhooksecret"
+		feed    = "https://calendar.example.com/private-feedsecret/basic.ics?token=querysecret"
+	)
+	t.Setenv("CLOCKIFY_API_KEY", apiKey)
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("fill", flag.ContinueOnError)
+	fs.String("webhook-url", "", "")
+	fs.String("calendar-ics", "", "")
+	fs.String("meetings-ics", "", "")
+	apiOpts := addAPIFlags(fs)
+	if err := fs.Parse([]string{"--webhook-url", webhook, "--calendar-ics", feed, "--meetings-ics", "work.ics", "--env-file", envFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout()
+	dumpConfig(fs, apiOpts, config{}, "", time.Now(), time.Now())
+	dumped := string(output())
+
+	for _, secret := range []string{apiKey, "webhooksecret", "feedsecret", "querysecret"} {
+		if strings.Contains(dumped, secret) {
+			t.Errorf("dumped config contains %q:\n%s", secret, dumped)
+		}
+	}
+	for _, kept := range []string{`webhook-url: "https://hooks.slack.com/****"`, `meetings-ics: "work.ics"`, "****1234"} {
+		if !strings.Contains(dumped, kept) {
+			t.Errorf("dumped config lacks %q:\n%s", kept, dumped)
+		}
+	}
+}
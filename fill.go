@@ -0,0 +1,913 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// dailyBlockFlags are the fill flags that shape, describe or select daily
+// blocks; meetings are logged as the calendar has them, so they're refused
+// with --meetings-ics rather than ignored.
+var dailyBlockFlags = []string{
+	"default-description", "description-cycle", "descriptions-file", "description-from-commits",
+	"append-task-name-to-description", "expand-env", "calendar-ics", "skip-time-off",
+	"preview-calendar", "expected-daily-hours", "monthly-target-hours", "weekly-hours",
+	"seconds-jitter", "start-jitter", "break-note", "deduct-break", "include-dates", "half-days",
+	"recreate-dates", "start-time", "end-time", "wall-clock-hours", "export-ics", "no-skip",
+	"append", "duplicate-ok", "only-empty-days", "only-missing", "copy-from-month",
+}
+
+func runFill(args []string) {
+	fs := flag.NewFlagSet("fill", flag.ExitOnError)
+	projectNames := &stringList{}
+	fs.Var(projectNames, "project", "project to fill, matched by name ignoring case; repeat to fill several projects, each with its hours from the config file (default: choose from a menu)")
+	projectRegex := fs.String("project-regex", "", "select the only project whose name matches this regular expression, e.g. '^ACME-(Dev|Ops)$'")
+	client := fs.String("client", "", "only offer and match projects of this client")
+	groupByClient := fs.Bool("group-by-client", false, "group the project menu by client")
+	taskID := fs.String("task-id", "", "ID of the task to log against; it must belong to the selected project (default: choose from a menu)")
+	taskName := fs.String("task", "", "name of the task to log against, matched like --project (default: the config file's task, or choose from a menu)")
+	noTask := fs.Bool("no-task", false, "log without a task, skipping the task menu and any task in the config file (same as --task-id \"\")")
+	includeDoneTasks := fs.Bool("include-done-tasks", false, "offer tasks marked as done in the task menu and when matching --task or the config file's task")
+	prefixTaskName := fs.Bool("append-task-name-to-description", false, "start each description with the selected task's name in brackets, e.g. \"[Backend] Standard workday\"")
+	defaultDescriptionFlag := fs.String("default-description", "", "description used instead of \"Standard workday\" by description option 1 and offered by the prompts")
+	fallbackDescription := fs.String("fallback-description", "", "description to retry with when the workspace rejects an entry's description, e.g. because descriptions are required")
+	descriptionCycleFlag := fs.String("description-cycle", "", "comma-separated descriptions assigned round-robin across working days")
+	descriptionsFile := fs.String("descriptions-file", "", "file of \"YYYY-MM-DD: description\" lines; days listed there use that description")
+	descriptionFromCommits := fs.String("description-from-commits", "", "path of a git repository; each day's description is made from that day's commit subjects by your user.email there, or the default on days without commits")
+	expandEnv := fs.Bool("expand-env", false, "expand $VAR references in descriptions from the environment (including .env)")
+	calendarICS := fs.String("calendar-ics", "", "iCal feed URL or file; days covered by all-day out-of-office/holiday events are not filled")
+	meetingsICS := fs.String("meetings-ics", "", "iCal feed URL or file; create one entry per timed event in the range, described by its title, instead of filling daily blocks")
+	meetingsExclude := fs.String("meetings-exclude", "", "with --meetings-ics, skip events whose title matches this regular expression, e.g. '(?i)lunch|focus time'")
+	skipTimeOff := fs.Bool("skip-time-off", false, "don't fill days covered by your approved time off in Clockify")
+	previewCalendar := fs.Bool("preview-calendar", false, "show the plan as a month calendar (✓ will fill, = already exists) before creating entries")
+	expectedDailyHours := fs.Float64("expected-daily-hours", 0, "warn before filling if each day's entry doesn't add up to this many hours, e.g. 8")
+	monthlyTargetHours := fs.Float64("monthly-target-hours", 0, "fill days in order only until the hours logged in the range, existing plus new, reach this target, e.g. 150")
+	weeklyHours := fs.Float64("weekly-hours", 0, "lengthen or shorten the entry on the last day of each week in the range so the week's logged time, existing plus new, totals this many hours, e.g. 37.5")
+	secondsJitter := fs.Duration("seconds-jitter", 0, "offset each entry's start and end by a random amount up to this duration, e.g. 3m (default: exact times)")
+	startJitter := fs.Duration("start-jitter", 0, "move each entry earlier or later by up to this duration in whole minutes, keeping its length, e.g. 15m (default: exact times)")
+	jitterSeed := fs.Int64("jitter-seed", 1, "seed for --seconds-jitter and --start-jitter; the same seed always gives the same offsets for a date")
+	breakNote := fs.Duration("break-note", 0, "note an unpaid break of this length in each description, e.g. 1h appends \"(incl. 1h unpaid lunch)\"")
+	deductBreak := fs.Bool("deduct-break", false, "shorten each entry by the --break-note length instead of only noting it")
+	includeDatesFlag := fs.String("include-dates", "", "comma-separated dates to fill even if they are weekends or excluded days, e.g. a Saturday worked for a deadline")
+	halfDaysFlag := fs.String("half-days", "", "comma-separated partial working days, each date or date=fraction, e.g. 2024-12-24 or 2024-12-31=0.25; the entry lasts that fraction of the full day (default 0.5) from the start time")
+	recreateDatesFlag := fs.String("recreate-dates", "", "comma-separated dates whose ClockiFill entries are deleted and created again with the current settings")
+	startTimeFlag := fs.String("start-time", "", "time each entry starts, e.g. 13:00 or 1:00pm (default: 09:00 or the project's start in the config file)")
+	endTimeFlag := fs.String("end-time", "", "time each entry ends, e.g. 17:00 or 5pm (default: 16:30 or the project's end in the config file)")
+	wallClockHours := fs.Bool("wall-clock-hours", false, "make each entry last exactly the configured length, ending that long after its start, even when a daylight saving change falls within it (default: end at the configured end time)")
+	dryRun := fs.Bool("dry-run", false, "show what would be created without creating anything")
+	dumpRequests := fs.Bool("dump-requests", false, "with --dry-run, print each entry's create request as a curl command")
+	exportICS := fs.String("export-ics", "", "write the planned entries to this iCalendar file for review, without creating anything")
+	noSkip := fs.Bool("no-skip", false, "with --dry-run, also list the days that would be skipped, with the entry they would get")
+	appendBlock := fs.Bool("append", false, "add the entries even on days that already have entries; requires --start-time and --end-time")
+	duplicateOK := fs.Bool("duplicate-ok", false, "allow a second ClockiFill entry on days that already have one (entries overlapping on the same project are still skipped)")
+	submit := fs.Bool("submit", false, "after a fill without failures, submit the timesheet for approval")
+	approvalPeriod := fs.String("approval-period", "monthly", "approval period submitted by --submit: weekly, semi_monthly or monthly")
+	onlyEmptyDays := fs.Bool("only-empty-days", false, "fill only days with no time logged on any project, so days logged elsewhere are left alone")
+	onlyMissing := fs.Bool("only-missing", false, "list the working days in the range with no entry on any project, then exit without filling")
+	copyFromMonth := fs.String("copy-from-month", "", "recreate the entries of the given month (YYYY-MM) on the matching days of the fill range")
+	verifyAfter := fs.Bool("verify-after", false, "read the created entries back afterwards and report any that are missing or have the wrong duration")
+	order := fs.String("order", "chronological", "order in which entries are created: chronological or reverse (newest first)")
+	timeoutPerDay := fs.Duration("timeout-per-day", 0, "give up on a day's entry if creating it takes longer than this in total, retries included, e.g. 2m (default: no limit)")
+	readConcurrency := fs.Int("read-concurrency", 4, "how many days to check for existing entries at once")
+	writeConcurrency := fs.Int("write-concurrency", 1, "how many entries to create at once")
+	configPath := fs.String("config", "", "config file with per-project settings (default: ~/.clockifill.yaml if it exists)")
+	dumpConfigFlag := fs.Bool("dump-config", false, "print the settings that would be used, with where each came from, then exit without doing anything")
+	tagsFlag := fs.String("tags", "", "comma-separated names of existing tags to attach to every entry")
+	billableFlag := fs.String("billable", "", "yes or no to set whether entries are billable, or auto to use the config file's setting, then the workspace's default, then no (default: ask)")
+	billableTagsFlag := fs.String("billable-tags", "", "tag=true|false pairs deciding billable from the --tags attached, e.g. billable=true,internal=false")
+	sourceField := fs.String("set-source-field", "", "name=value of an existing custom field to set on every created entry, e.g. Source=ClockiFill, so reports and delete --source-field can tell them apart")
+	draft := fs.Bool("draft", false, "tag the created entries \"draft\" so they can be reviewed and finalized in Clockify")
+	markerTag := fs.String("marker-tag", "clockifill", "tag added to every created entry to identify it as ClockiFill's own (empty to disable)")
+	summaryOnlyOnChange := fs.Bool("summary-only-on-change", false, "print nothing unless entries were added or something failed, and exit with status 1 on failure; for cron jobs that mail their output")
+	webhookURL := fs.String("webhook-url", "", "POST the outcome of the fill as JSON to this URL when it finishes")
+	webhookFormat := fs.String("webhook-format", "json", "body posted to --webhook-url: json, or slack for a Slack incoming webhook")
+	logFile := fs.String("log-file", "", "also append the output of the run to this file")
+	logMaxSize := fs.Int64("log-max-size", 10, "size in MB after which --log-file is moved to <file>.1 and started afresh")
+	noColor := fs.Bool("no-color", false, "don't color project and task names (also off when NO_COLOR is set or output isn't a terminal)")
+	maxDaysBack := fs.Int("max-days-back", 730, "refuse to fill a range starting more than this many days ago, which is usually a typo in --from")
+	force := fs.Bool("force", false, "fill even if the range starts more than --max-days-back days ago")
+	noLock := fs.Bool("no-lock", false, "don't take the lock that stops two runs from filling at the same time")
+	rangeOpts := addRangeFlags(fs)
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	if *logFile != "" {
+		stop, err := startLog(*logFile, *logMaxSize<<20)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer stop()
+	}
+
+	// Quiet runs hold back all output until it's known whether anything
+	// changed; runs that stop early count as failed
+	changed, failed := true, true
+	if *summaryOnlyOnChange {
+		output := captureStdout()
+		defer func() {
+			if log := output(); changed || failed {
+				os.Stdout.Write(log)
+			}
+			if failed {
+				exit(1)
+			}
+		}()
+	}
+
+	// An explicitly empty --task-id means no task, like --no-task
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "task-id" && *taskID == "" {
+			*noTask = true
+		}
+	})
+
+	descriptionCycle := splitList(*descriptionCycleFlag)
+
+	// Collect every problem with the flags so they can all be reported at once
+	now := time.Now()
+	rangeStart, rangeEnd, problems := rangeOpts.resolve(now)
+	horizon := time.Date(now.Year(), now.Month(), now.Day()-*maxDaysBack, 0, 0, 0, 0, now.Location())
+	if rangeStart.Before(horizon) && !*force {
+		problems = append(problems, fmt.Errorf("the range starts %s, more than %d days ago; pass --force if that's intended, or fix --from",
+			rangeStart.Format("2006-01-02"), *maxDaysBack))
+	}
+	var recreateDates []time.Time
+	for _, value := range splitList(*recreateDatesFlag) {
+		if date, err := parseDate(value, *rangeOpts.dateLayout); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --recreate-dates: %v", err))
+		} else {
+			recreateDates = append(recreateDates, date)
+		}
+	}
+	var includeDates []time.Time
+	for _, value := range splitList(*includeDatesFlag) {
+		date, err := parseDate(value, *rangeOpts.dateLayout)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid --include-dates: %v", err))
+			continue
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, rangeStart.Location())
+		if date.Before(rangeStart) || date.After(rangeEnd) {
+			problems = append(problems, fmt.Errorf("invalid --include-dates: %s is outside the range %s to %s",
+				value, rangeStart.Format("2006-01-02"), rangeEnd.Format("2006-01-02")))
+			continue
+		}
+		includeDates = append(includeDates, date)
+	}
+	dayFractions := make(map[string]float64)
+	for _, value := range splitList(*halfDaysFlag) {
+		dateValue, fractionValue, hasFraction := strings.Cut(value, "=")
+		date, err := parseDate(strings.TrimSpace(dateValue), *rangeOpts.dateLayout)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid --half-days: %v", err))
+			continue
+		}
+		fraction := 0.5
+		if hasFraction {
+			fraction, err = strconv.ParseFloat(strings.TrimSpace(fractionValue), 64)
+			if err != nil || fraction <= 0 || fraction > 1 {
+				problems = append(problems, fmt.Errorf("invalid --half-days: fraction %q for %s must be a number more than 0 and at most 1", fractionValue, dateValue))
+				continue
+			}
+		}
+		dayFractions[date.Format("2006-01-02")] = fraction
+	}
+	if len(recreateDates) > 0 && *markerTag == "" {
+		problems = append(problems, fmt.Errorf("--recreate-dates requires --marker-tag to identify ClockiFill's entries"))
+	}
+	if *submit {
+		if _, err := clockify.PeriodStart(*approvalPeriod, now, rangeOpts.firstWeekday()); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --approval-period: %v", err))
+		}
+	}
+	var projectPattern *regexp.Regexp
+	if *projectRegex != "" {
+		if len(*projectNames) > 0 {
+			problems = append(problems, fmt.Errorf("--project-regex cannot be combined with --project"))
+		}
+		pattern, err := regexp.Compile(*projectRegex)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid --project-regex: %v", err))
+		}
+		projectPattern = pattern
+	}
+	var meetingsExcludePattern *regexp.Regexp
+	if *meetingsExclude != "" {
+		if *meetingsICS == "" {
+			problems = append(problems, fmt.Errorf("--meetings-exclude requires --meetings-ics"))
+		}
+		pattern, err := regexp.Compile(*meetingsExclude)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("invalid --meetings-exclude: %v", err))
+		}
+		meetingsExcludePattern = pattern
+	}
+	if *meetingsICS != "" {
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		var conflicting []string
+		for _, name := range dailyBlockFlags {
+			if set[name] {
+				conflicting = append(conflicting, "--"+name)
+			}
+		}
+		if len(conflicting) > 0 {
+			problems = append(problems, fmt.Errorf("--meetings-ics cannot be combined with %s, which only apply to daily blocks", strings.Join(conflicting, ", ")))
+		}
+	}
+	if *noTask && (*taskID != "" || *taskName != "") {
+		problems = append(problems, fmt.Errorf("--no-task cannot be combined with --task-id or --task"))
+	}
+	if *taskID != "" && *taskName != "" {
+		problems = append(problems, fmt.Errorf("--task cannot be combined with --task-id"))
+	}
+	if len(*projectNames) > 1 && *taskName != "" {
+		problems = append(problems, fmt.Errorf("--task cannot be used with several --project values; set each project's task in the config file"))
+	}
+	if len(*projectNames) > 1 && *taskID != "" {
+		problems = append(problems, fmt.Errorf("--task-id cannot be used with several --project values; set each project's task in the config file"))
+	}
+	startTime, err := parseClock(*startTimeFlag)
+	if err != nil {
+		problems = append(problems, fmt.Errorf("invalid --start-time: %v", err))
+	}
+	endTime, err := parseClock(*endTimeFlag)
+	if err != nil {
+		problems = append(problems, fmt.Errorf("invalid --end-time: %v", err))
+	}
+	if *appendBlock && (*startTimeFlag == "" || *endTimeFlag == "") {
+		problems = append(problems, fmt.Errorf("--append requires --start-time and --end-time so the same span isn't added twice by accident"))
+	}
+	if len(*projectNames) > 1 && (*startTimeFlag != "" || *endTimeFlag != "") {
+		problems = append(problems, fmt.Errorf("--start-time and --end-time cannot be used with several --project values; set each project's hours in the config file"))
+	}
+	if *exportICS != "" {
+		if *submit {
+			problems = append(problems, fmt.Errorf("--export-ics cannot be combined with --submit"))
+		}
+		if *copyFromMonth != "" || *onlyMissing {
+			problems = append(problems, fmt.Errorf("--export-ics cannot be combined with --copy-from-month or --only-missing"))
+		}
+		*dryRun = true
+	}
+	if *dumpRequests && !*dryRun {
+		problems = append(problems, fmt.Errorf("--dump-requests only works with --dry-run"))
+	}
+	if *noSkip && !*dryRun {
+		problems = append(problems, fmt.Errorf("--no-skip only works with --dry-run"))
+	}
+	if *dryRun && *submit {
+		problems = append(problems, fmt.Errorf("--dry-run cannot be combined with --submit"))
+	}
+	if *dryRun && *copyFromMonth != "" {
+		problems = append(problems, fmt.Errorf("--dry-run cannot be combined with --copy-from-month, which has no dry run"))
+	}
+	if *draft && *submit {
+		problems = append(problems, fmt.Errorf("--draft cannot be combined with --submit: review the drafts before submitting"))
+	}
+	if *webhookFormat != "json" && *webhookFormat != "slack" {
+		problems = append(problems, fmt.Errorf("invalid --webhook-format %q: expected json or slack", *webhookFormat))
+	}
+	if *order != "chronological" && *order != "reverse" {
+		problems = append(problems, fmt.Errorf("invalid --order %q: expected chronological or reverse", *order))
+	}
+	if *onlyMissing && *copyFromMonth != "" {
+		problems = append(problems, fmt.Errorf("--only-missing cannot be combined with --copy-from-month"))
+	}
+	switch *billableFlag {
+	case "", "yes", "no", "auto":
+	default:
+		problems = append(problems, fmt.Errorf("invalid --billable %q: expected yes, no or auto", *billableFlag))
+	}
+	var sourceFieldName, sourceFieldValue string
+	if *sourceField != "" {
+		if sourceFieldName, sourceFieldValue, err = parseFieldSetting(*sourceField); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --set-source-field: %v", err))
+		}
+	}
+	tagNames := splitList(*tagsFlag)
+	billableTags, err := parseBillableTags(*billableTagsFlag)
+	if err != nil {
+		problems = append(problems, fmt.Errorf("invalid --billable-tags: %v", err))
+	}
+	tagBillable, billableTag, err := billableFromTags(tagNames, billableTags)
+	if err != nil {
+		problems = append(problems, err)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Invalid options:\n%v\n", errors.Join(problems...))
+		return
+	}
+	if emptyRange(rangeStart, rangeEnd) {
+		changed, failed = false, false
+		return
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if *dumpConfigFlag {
+		dumpConfig(fs, apiOpts, cfg, *configPath, rangeStart, rangeEnd)
+		changed, failed = true, false
+		return
+	}
+
+	var datedDescriptions map[string]string
+	if *descriptionsFile != "" {
+		if datedDescriptions, err = readDescriptionsFile(*descriptionsFile); err != nil {
+			fmt.Printf("Error reading descriptions file: %v\n", err)
+			return
+		}
+	}
+
+	useColor = useColor && !*noColor
+
+	if !*noLock && !*onlyMissing {
+		release, err := acquireLock()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer release()
+	}
+
+	workingDays := clockify.GetWorkingDays(rangeStart, rangeEnd)
+	if *calendarICS != "" {
+		events, err := readCalendar(*calendarICS)
+		if err != nil {
+			fmt.Printf("Error reading calendar: %v\n", err)
+			return
+		}
+		workingDays = clockify.ExcludeDays(workingDays, calendarDaysOff(events), os.Stdout)
+	}
+	var meetings []clockify.Meeting
+	if *meetingsICS != "" {
+		events, err := readCalendar(*meetingsICS)
+		if err != nil {
+			fmt.Printf("Error reading meetings calendar: %v\n", err)
+			return
+		}
+		meetings = calendarMeetings(events, rangeStart, rangeEnd, meetingsExcludePattern)
+		fmt.Printf("Found %d meetings to log\n", len(meetings))
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	if *skipTimeOff {
+		timeOff, err := api.ApprovedTimeOff(rangeStart, rangeEnd)
+		if err != nil {
+			fmt.Printf("Error getting time off: %v\n", err)
+			return
+		}
+		workingDays = clockify.ExcludeDays(workingDays, timeOff, os.Stdout)
+	}
+	if len(includeDates) > 0 {
+		workingDays = clockify.IncludeDays(workingDays, includeDates, os.Stdout)
+	}
+
+	// Descriptions from the descriptions file win over those from commits
+	if *descriptionFromCommits != "" {
+		fromCommits, err := commitDescriptions(*descriptionFromCommits, workingDays)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if datedDescriptions == nil {
+			datedDescriptions = make(map[string]string)
+		}
+		for date, description := range fromCommits {
+			if _, ok := datedDescriptions[date]; !ok {
+				datedDescriptions[date] = description
+			}
+		}
+		fmt.Printf("Using commit messages as the description for %d of %d days\n", len(fromCommits), len(workingDays))
+	}
+
+	if sourceFieldName != "" {
+		if err := api.SetSourceField(sourceFieldName, sourceFieldValue); err != nil {
+			fmt.Printf("Error looking up --set-source-field: %v\n", err)
+			return
+		}
+	}
+
+	var tagIDs []string
+	if len(tagNames) > 0 {
+		if tagIDs, err = api.ResolveTagIDs(tagNames); err != nil {
+			fmt.Printf("Error looking up --tags: %v\n", err)
+			return
+		}
+	}
+
+	if *onlyMissing {
+		missing, err := clockify.MissingDays(api, workingDays)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(missing) == 0 {
+			fmt.Println("Missing: none")
+		} else {
+			var dates []string
+			for _, day := range missing {
+				dates = append(dates, day.Format("2006-01-02"))
+			}
+			fmt.Printf("Missing: %s\n", strings.Join(dates, ", "))
+		}
+		changed, failed = len(missing) > 0, false
+		return
+	}
+
+	if *copyFromMonth != "" {
+		summary, err := clockify.CopyMonth(api, *copyFromMonth, workingDays, *markerTag, os.Stdout)
+		if err != nil {
+			fmt.Printf("Error copying entries: %v\n", err)
+			return
+		}
+		fmt.Printf("\nSummary: %s\n", summary)
+		printRetryStats(api)
+		changed, failed = summary.Added > 0, summary.Failed > 0
+		return
+	}
+
+	// Get projects
+	projects, err := api.GetProjects()
+	if err != nil {
+		fmt.Printf("Error getting projects: %v\n", err)
+		return
+	}
+
+	if *client != "" {
+		if projects, err = clockify.FilterByClient(projects, *client); err != nil {
+			fmt.Printf("Error selecting client: %v\n", err)
+			return
+		}
+	}
+
+	var selectedProjects []clockify.Project
+	for _, name := range *projectNames {
+		project, err := clockify.FindProject(projects, name)
+		if err != nil {
+			fmt.Printf("Error selecting project: %v\n", err)
+			return
+		}
+		selectedProjects = append(selectedProjects, project)
+	}
+	if projectPattern != nil {
+		project, err := clockify.FindProjectByRegex(projects, projectPattern)
+		if err != nil {
+			fmt.Printf("Error selecting project: %v\n", err)
+			return
+		}
+		fmt.Printf("\nUsing project: %s\n", tint(project.Name, project.Color))
+		selectedProjects = append(selectedProjects, project)
+	}
+	if len(selectedProjects) == 0 && cfg.DefaultProject != "" {
+		project, err := clockify.FindProject(projects, cfg.DefaultProject)
+		if err != nil {
+			fmt.Printf("Error selecting the config file's default_project: %v\n", err)
+			return
+		}
+		fmt.Printf("\nUsing project: %s (default_project in the config file)\n", tint(project.Name, project.Color))
+		selectedProjects = append(selectedProjects, project)
+	}
+	if len(selectedProjects) == 0 {
+		fmt.Println("\nAvailable Projects:")
+		if *groupByClient {
+			clockify.SortByClient(projects)
+		}
+		for i, label := range clockify.ProjectLabels(projects) {
+			if *groupByClient && (i == 0 || projects[i].ClientName != projects[i-1].ClientName) {
+				clientName := projects[i].ClientName
+				if clientName == "" {
+					clientName = "No client"
+				}
+				fmt.Printf("\n%s:\n", clientName)
+			}
+			fmt.Printf("%d. %s\n", i+1, tint(label, projects[i].Color))
+		}
+
+		// Select project
+		var projectIdx int
+		for {
+			fmt.Print("\nSelect project number: ")
+			projectIdx, _ = strconv.Atoi(readLine())
+			projectIdx--
+			if projectIdx >= 0 && projectIdx < len(projects) {
+				break
+			}
+			exitIfNoInput()
+			fmt.Printf("Please enter a number between 1 and %d\n", len(projects))
+		}
+
+		selectedProjects = append(selectedProjects, projects[projectIdx])
+	}
+
+	// Several projects are filled side by side on the same days, so each
+	// needs its own hours from the config file
+	multiple := len(selectedProjects) > 1
+	var projectMeetings map[string][]clockify.Meeting
+	if *meetingsICS != "" {
+		if projectMeetings, err = routeMeetings(meetings, selectedProjects, cfg); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	} else if multiple {
+		if err := checkProjectHours(cfg, selectedProjects); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+
+	// Billable is asked once for the projects that don't set it in the
+	// config file
+	var billable bool
+	for _, project := range selectedProjects {
+		if multiple && *billableFlag == "" && tagBillable == nil && cfg.project(project.Name).Billable == nil {
+			billable = getBillablePreference()
+			break
+		}
+	}
+
+	var summary clockify.Summary
+	var breakdown []string
+	var export *clockify.CalendarExport
+	if *exportICS != "" {
+		export = &clockify.CalendarExport{}
+	}
+	for _, selectedProject := range selectedProjects {
+		projectCfg := cfg.project(selectedProject.Name)
+		if len(*projectNames) > 0 {
+			fmt.Printf("\nUsing project: %s\n", tint(selectedProject.Name, selectedProject.Color))
+		}
+
+		// Get tasks
+		tasks, err := api.GetTasks(selectedProject.ID)
+		if err != nil {
+			fmt.Printf("Error getting tasks: %v\n", err)
+			return
+		}
+
+		// Done tasks are left out of the menu and name matching, but a task
+		// given by ID is used whatever its status
+		allTasks := tasks
+		if !*includeDoneTasks {
+			tasks = clockify.ActiveTasks(tasks)
+		}
+
+		var selectedTask *clockify.Task
+		switch {
+		case *noTask:
+			fmt.Println("Logging without a task")
+		case *taskID != "":
+			task, err := clockify.FindTaskByID(allTasks, *taskID, selectedProject)
+			if err != nil {
+				fmt.Printf("Error selecting task: %v\n", err)
+				return
+			}
+			selectedTask = &task
+			fmt.Printf("Using task: %s\n", tint(task.Name, task.Color))
+		case *taskName != "" || projectCfg.Task != "":
+			name := *taskName
+			if name == "" {
+				name = projectCfg.Task
+			}
+			task, err := clockify.FindTaskByName(tasks, name, selectedProject)
+			if err != nil {
+				if _, doneErr := clockify.FindTaskByName(allTasks, name, selectedProject); doneErr == nil {
+					err = fmt.Errorf("task %q is done; pass --include-done-tasks to log against it", name)
+				}
+				fmt.Printf("Error selecting task: %v\n", err)
+				return
+			}
+			selectedTask = &task
+			fmt.Printf("Using task: %s\n", tint(task.Name, task.Color))
+		case multiple:
+			// No menus when filling several projects
+		case len(tasks) > 0:
+			fmt.Println("\nAvailable Tasks:")
+			for i, task := range tasks {
+				fmt.Printf("%d. %s\n", i+1, tint(task.Name, task.Color))
+			}
+
+			fmt.Print("\nPress Enter to skip task selection or enter a task number: ")
+			taskInput := readLine()
+
+			if taskInput != "" {
+				taskIdx, err := strconv.Atoi(taskInput)
+				if err == nil && taskIdx > 0 && taskIdx <= len(tasks) {
+					selectedTask = &tasks[taskIdx-1]
+				} else {
+					fmt.Println("Invalid task number, proceeding without task selection")
+				}
+			}
+		default:
+			fmt.Println("\nNo tasks found for this project, proceeding without task selection")
+		}
+
+		projectStart, projectEnd, err := projectCfg.hours()
+		if err != nil {
+			fmt.Printf("Error in config for project %s: %v\n", selectedProject.Name, err)
+			return
+		}
+		if *startTimeFlag != "" {
+			projectStart = startTime
+		}
+		if *endTimeFlag != "" {
+			projectEnd = endTime
+		}
+
+		defaultDescription := clockify.DefaultDescription
+		switch {
+		case *defaultDescriptionFlag != "":
+			defaultDescription = *defaultDescriptionFlag
+		case projectCfg.Description != "":
+			defaultDescription = projectCfg.Description
+		case cfg.DefaultDescription != "":
+			defaultDescription = cfg.DefaultDescription
+		}
+
+		descriptionMode := 1
+		if len(descriptionCycle) == 0 && !multiple && *meetingsICS == "" {
+			descriptionMode = getDescriptionMode(defaultDescription)
+		}
+		projectBillable, billableSource := billable, "chosen for all projects"
+		switch {
+		case *billableFlag == "yes" || *billableFlag == "no":
+			projectBillable, billableSource = *billableFlag == "yes", "from --billable"
+		case tagBillable != nil:
+			projectBillable, billableSource = *tagBillable, fmt.Sprintf("from the %s tag", billableTag)
+		case projectCfg.Billable != nil:
+			projectBillable, billableSource = *projectCfg.Billable, "from the config file"
+		case *billableFlag == "auto":
+			workspaceBillable, err := api.DefaultBillable()
+			if err != nil {
+				fmt.Printf("Error getting the workspace's billable default: %v\n", err)
+				return
+			}
+			projectBillable, billableSource = workspaceBillable, "from the workspace's default"
+		case !multiple:
+			projectBillable, billableSource = getBillablePreference(), "chosen"
+		}
+		fmt.Printf("Billable for %s: %s (%s)\n", selectedProject.Name, yesNo(projectBillable), billableSource)
+
+		opts := clockify.FillOptions{
+			API:                 api,
+			Days:                workingDays,
+			Project:             selectedProject,
+			Billable:            projectBillable,
+			StartTime:           projectStart,
+			EndTime:             projectEnd,
+			ExactTimes:          true,
+			Description:         defaultDescription,
+			DescriptionCycle:    descriptionCycle,
+			DatedDescriptions:   datedDescriptions,
+			ExpandEnv:           *expandEnv,
+			FallbackDescription: *fallbackDescription,
+			PrefixTaskName:      *prefixTaskName,
+			BreakNote:           *breakNote,
+			DeductBreak:         *deductBreak,
+			KeepDuration:        *wallClockHours,
+			DayFractions:        dayFractions,
+			Reverse:             *order == "reverse",
+			VerifyAfter:         *verifyAfter,
+			TimeoutPerDay:       *timeoutPerDay,
+			ReadConcurrency:     *readConcurrency,
+			WriteConcurrency:    *writeConcurrency,
+			Color:               useColor,
+			PreviewCalendar:     *previewCalendar,
+			ExpectedDailyHours:  *expectedDailyHours,
+			TargetHours:         *monthlyTargetHours,
+			WeeklyHours:         *weeklyHours,
+			WeekStart:           rangeOpts.firstWeekday(),
+			SecondsJitter:       *secondsJitter,
+			JitterSeed:          *jitterSeed,
+			StartJitter:         *startJitter,
+			MarkerTag:           *markerTag,
+			DraftTag:            draftTag(*draft),
+			TagIDs:              tagIDs,
+			DuplicateOK:         *duplicateOK,
+			Append:              *appendBlock,
+			OnlyEmptyDays:       *onlyEmptyDays,
+			DryRun:              *dryRun,
+			Export:              export,
+			DumpRequests:        *dumpRequests,
+			NoSkip:              *noSkip,
+			RecreateDates:       recreateDates,
+			Output:              os.Stdout,
+		}
+		if selectedTask != nil {
+			opts.TaskID = selectedTask.ID
+			opts.TaskName = selectedTask.Name
+		}
+		if multiple {
+			// The projects' entries share days, so only the overlap check on
+			// the same project guards against duplicates
+			opts.DuplicateOK = true
+		}
+
+		if *meetingsICS != "" {
+			projectSummary, err := clockify.FillMeetings(opts, projectMeetings[selectedProject.ID])
+			if err != nil {
+				fmt.Printf("Error logging meetings: %v\n", err)
+				return
+			}
+			if multiple {
+				fmt.Printf("\nSummary for %s: %s\n", selectedProject.Name, projectSummary)
+			}
+			summary.Add(projectSummary)
+			breakdown = append(breakdown, fmt.Sprintf("%s: +%d meetings/%sh", selectedProject.Name, projectSummary.Added, formatHours(projectSummary.AddedTime)))
+			continue
+		}
+
+		switch descriptionMode {
+		case 2:
+			fmt.Println()
+			opts.Description = promptDefault("Enter the description to use for all entries", defaultDescription)
+		case 3:
+			opts.DescriptionPrompt = promptDescription
+		case 4:
+			edited, err := editDescriptions(workingDays, defaultDescription, datedDescriptions)
+			if err != nil {
+				fmt.Printf("Error editing descriptions: %v\n", err)
+				return
+			}
+			opts.DatedDescriptions = edited
+		}
+
+		projectSummary, err := clockify.Fill(opts)
+		if err != nil {
+			fmt.Printf("Error filling time entries: %v\n", err)
+			return
+		}
+
+		if multiple {
+			fmt.Printf("\nSummary for %s: %s\n", selectedProject.Name, projectSummary)
+		}
+		summary.Add(projectSummary)
+		breakdown = append(breakdown, fmt.Sprintf("%s: +%dd/%sh", selectedProject.Name, projectSummary.Added, formatHours(projectSummary.AddedTime)))
+	}
+
+	changed = summary.Added > 0
+	failed = summary.Failed > 0 || summary.Unverified > 0 || summary.FutureRejected > 0
+
+	if multiple {
+		fmt.Printf("\nTotal: %s\n", summary)
+		fmt.Printf("By project: %s\n", strings.Join(breakdown, ", "))
+	} else {
+		fmt.Printf("\nSummary: %s\n", summary)
+	}
+	printRetryStats(api)
+
+	// A failed notification doesn't make the fill itself fail
+	if *webhookURL != "" && !summary.DryRun {
+		payload := newWebhookPayload(summary, selectedProjects, rangeStart, rangeEnd)
+		if err := notifyWebhook(*webhookURL, *webhookFormat, payload); err != nil {
+			fmt.Printf("Warning: webhook notification failed: %v\n", err)
+		}
+	}
+
+	if export != nil {
+		if err := writeCalendarExport(*exportICS, export); err != nil {
+			fmt.Printf("Error writing %s: %v\n", *exportICS, err)
+			failed = true
+			return
+		}
+		fmt.Printf("Wrote %d planned entries to %s\n", export.Len(), *exportICS)
+	}
+
+	if *submit {
+		if summary.Failed > 0 || summary.Unverified > 0 || summary.FutureRejected > 0 {
+			fmt.Println("Not submitting for approval because some entries failed or couldn't be verified")
+			return
+		}
+
+		periodStart, err := clockify.PeriodStart(*approvalPeriod, rangeStart, rangeOpts.firstWeekday())
+		if err != nil {
+			fmt.Printf("Error submitting for approval: %v\n", err)
+			failed = true
+			return
+		}
+
+		approval, err := api.SubmitApproval(*approvalPeriod, periodStart)
+		if err != nil {
+			fmt.Printf("Error submitting for approval: %v\n", err)
+			failed = true
+			return
+		}
+		fmt.Printf("Submitted %s period starting %s for approval (request %s)\n",
+			strings.ToLower(*approvalPeriod), periodStart.Format("2006-01-02"), approval.ID)
+	}
+}
+
+// parseFieldSetting splits a "name=value" custom field setting.
+func parseFieldSetting(setting string) (string, string, error) {
+	name, value, ok := strings.Cut(setting, "=")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || name == "" || value == "" {
+		return "", "", fmt.Errorf("expected name=value, got %q", setting)
+	}
+	return name, value, nil
+}
+
+// formatHours formats a duration as hours with at most two decimals, e.g.
+// "112.5".
+func formatHours(d time.Duration) string {
+	return strconv.FormatFloat(math.Round(d.Hours()*100)/100, 'f', -1, 64)
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// parseBillableTags parses "name=true,other=false" into a map from the
+// lowercased tag name to whether it makes entries billable.
+func parseBillableTags(value string) (map[string]bool, error) {
+	billableTags := make(map[string]bool)
+	for _, item := range splitList(value) {
+		name, setting, ok := strings.Cut(item, "=")
+		billable, err := strconv.ParseBool(strings.TrimSpace(setting))
+		if !ok || err != nil || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%q: expected tag=true or tag=false", item)
+		}
+		billableTags[strings.ToLower(strings.TrimSpace(name))] = billable
+	}
+	return billableTags, nil
+}
+
+// billableFromTags returns whether the attached tags make entries billable,
+// and the tag that decided it, or nil if none of them is mapped. Tags that
+// disagree are an error.
+func billableFromTags(tagNames []string, billableTags map[string]bool) (*bool, string, error) {
+	var billable *bool
+	var decidedBy string
+	for _, name := range tagNames {
+		value, ok := billableTags[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		if billable != nil && *billable != value {
+			return nil, "", fmt.Errorf("tags %s and %s disagree on whether entries are billable", decidedBy, name)
+		}
+		billable, decidedBy = &value, name
+	}
+	return billable, decidedBy, nil
+}
+
+// draftTag returns the tag that marks entries as drafts when --draft is set.
+func draftTag(draft bool) string {
+	if draft {
+		return "draft"
+	}
+	return ""
+}
+
+func getDescriptionMode(defaultDescription string) int {
+	fmt.Println("\nHow would you like to handle task descriptions?")
+	fmt.Printf("1. Use default description ('%s') for all entries\n", defaultDescription)
+	fmt.Println("2. Set one custom description for all entries")
+	fmt.Println("3. Enter custom description for each day (press Enter to reuse the previous one)")
+	fmt.Println("4. Write the description for each day in your editor ($EDITOR)")
+
+	for {
+		fmt.Print("\nEnter your choice (1-4): ")
+		choice, _ := strconv.Atoi(readLine())
+		if choice >= 1 && choice <= 4 {
+			return choice
+		}
+		exitIfNoInput()
+		fmt.Println("Please enter a valid choice (1-4)")
+	}
+}
+
+// promptDescription asks for a day's description, offering the previous one
+// as the default.
+func promptDescription(day time.Time, previous string) string {
+	fmt.Println()
+	return promptDefault("Enter description for "+day.Format("2006-01-02"), previous)
+}
+
+func getBillablePreference() bool {
+	fmt.Println()
+	return confirm("Make entries billable?")
+}
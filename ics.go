@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// Summaries of all-day calendar events that mark a day as not worked.
+var calendarDayOffKeywords = []string{"out of office", "holiday", "vacation", "annual leave", "day off"}
+
+type CalendarEvent struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+}
+
+// readCalendar reads an iCal feed from an http(s) URL or a local file path.
+func readCalendar(source string) ([]CalendarEvent, error) {
+	var reader io.Reader
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "webcal://") {
+		resp, err := http.Get(strings.Replace(source, "webcal://", "https://", 1))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch calendar: %s", resp.Status)
+		}
+		reader = resp.Body
+	} else {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	return parseCalendar(reader)
+}
+
+// parseCalendar extracts the VEVENTs from an iCal (RFC 5545) stream.
+// Recurrence rules are not expanded; only each event's first occurrence is
+// returned.
+func parseCalendar(reader io.Reader) ([]CalendarEvent, error) {
+	var lines []string
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Lines starting with whitespace continue the previous line
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []CalendarEvent
+	var event *CalendarEvent
+	for _, line := range lines {
+		name, params, value := splitCalendarLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &CalendarEvent{}
+		case name == "END" && value == "VEVENT" && event != nil:
+			if event.End.IsZero() {
+				event.End = event.Start
+				if event.AllDay {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+			}
+			if !event.Start.IsZero() {
+				events = append(events, *event)
+			}
+			event = nil
+		case event == nil:
+			continue
+		case name == "SUMMARY":
+			event.Summary = unescapeCalendarText(value)
+		case name == "DTSTART":
+			start, allDay, err := parseCalendarTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DTSTART %q: %v", value, err)
+			}
+			event.Start, event.AllDay = start, allDay
+		case name == "DTEND":
+			end, _, err := parseCalendarTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid DTEND %q: %v", value, err)
+			}
+			event.End = end
+		case name == "X-MICROSOFT-CDO-ALLDAYEVENT" && strings.EqualFold(value, "TRUE"):
+			event.AllDay = true
+		}
+	}
+
+	return events, nil
+}
+
+func splitCalendarLine(line string) (name string, params map[string]string, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	params = make(map[string]string)
+	for _, param := range parts[1:] {
+		if key, val, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func parseCalendarTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		date, err := time.ParseInLocation("20060102", value, time.Local)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.Local(), false, err
+	}
+
+	location := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if loc, err := time.LoadLocation(tzid); err == nil {
+			location = loc
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	return t, false, err
+}
+
+func unescapeCalendarText(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
+
+// writeCalendarExport writes the collected plan to the iCalendar file at path.
+func writeCalendarExport(path string, export *clockify.CalendarExport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// calendarDaysOff returns the dates (as YYYY-MM-DD) covered by all-day
+// out-of-office or holiday events, mapped to the event summary.
+func calendarDaysOff(events []CalendarEvent) map[string]string {
+	daysOff := make(map[string]string)
+	for _, event := range events {
+		if !event.AllDay || !isDayOffSummary(event.Summary) {
+			continue
+		}
+		for day := event.Start; day.Before(event.End); day = day.AddDate(0, 0, 1) {
+			daysOff[day.Format("2006-01-02")] = event.Summary
+		}
+	}
+	return daysOff
+}
+
+func isDayOffSummary(summary string) bool {
+	summary = strings.ToLower(summary)
+	for _, keyword := range calendarDayOffKeywords {
+		if strings.Contains(summary, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// calendarMeetings returns the timed events starting within the range as
+// meetings to log, in order, leaving out those whose title matches exclude.
+func calendarMeetings(events []CalendarEvent, rangeStart, rangeEnd time.Time, exclude *regexp.Regexp) []clockify.Meeting {
+	var meetings []clockify.Meeting
+	for _, event := range events {
+		if event.AllDay || !event.End.After(event.Start) || event.Start.Before(rangeStart) || event.Start.After(rangeEnd) {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(event.Summary) {
+			continue
+		}
+		meetings = append(meetings, clockify.Meeting{Title: event.Summary, Start: event.Start, End: event.End})
+	}
+	sort.Slice(meetings, func(i, j int) bool { return meetings[i].Start.Before(meetings[j].Start) })
+	return meetings
+}
+
+// routeMeetings assigns each meeting to the first project whose meetings
+// pattern in the config file matches its title. A project filled alone
+// without a pattern gets every meeting. Meetings no project takes are
+// reported and left out.
+func routeMeetings(meetings []clockify.Meeting, projects []clockify.Project, cfg config) (map[string][]clockify.Meeting, error) {
+	if len(projects) == 1 && cfg.project(projects[0].Name).Meetings == "" {
+		return map[string][]clockify.Meeting{projects[0].ID: meetings}, nil
+	}
+
+	patterns := make([]*regexp.Regexp, len(projects))
+	for i, project := range projects {
+		value := cfg.project(project.Name).Meetings
+		if value == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid meetings pattern for project %s: %v", project.Name, err)
+		}
+		patterns[i] = pattern
+	}
+
+	routed := make(map[string][]clockify.Meeting)
+	for _, meeting := range meetings {
+		taken := false
+		for i, pattern := range patterns {
+			if pattern != nil && pattern.MatchString(meeting.Title) {
+				routed[projects[i].ID] = append(routed[projects[i].ID], meeting)
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			fmt.Printf("Not logging %s %q - no project's meetings pattern matches it\n", meeting.Start.Format("2006-01-02 15:04"), meeting.Title)
+		}
+	}
+	return routed, nil
+}
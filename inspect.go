@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// runInspect prints the raw JSON Clockify returns for a day's entries, for
+// debugging entries that look wrong in the web UI.
+func runInspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	projectName := fs.String("project", "", "only show entries on this project (default: all projects)")
+	dateLayout := fs.String("date-layout", "2006-01-02", "Go time layout used to parse the date")
+	apiOpts := addAPIFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clockifill inspect [flags] <date>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	day, err := parseDate(fs.Arg(0), *dateLayout)
+	if err != nil {
+		fmt.Printf("Invalid date: %v\n", err)
+		return
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.Local)
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	var projectID string
+	if *projectName != "" {
+		projects, err := api.GetProjects()
+		if err != nil {
+			fmt.Printf("Error getting projects: %v\n", err)
+			return
+		}
+		project, err := clockify.FindProject(projects, *projectName)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		projectID = project.ID
+	}
+
+	body, err := api.GetRawDayEntries(projectID, day)
+	if err != nil {
+		fmt.Printf("Error getting time entries: %v\n", err)
+		return
+	}
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		// Not JSON; show it as it came
+		os.Stdout.Write(body)
+		fmt.Println()
+		return
+	}
+	pretty.WriteTo(os.Stdout)
+	fmt.Println()
+}
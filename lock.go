@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// acquireLock makes sure only one ClockiFill run changes entries at a time,
+// so overlapping runs can't both decide a day is empty and fill it twice.
+// The lock is a file in the user's cache directory holding the owner's PID;
+// the returned function removes it. It is also removed on Ctrl+C.
+func acquireLock() (func(), error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find cache directory for the lock file: %v", err)
+	}
+	dir := filepath.Join(cacheDir, "clockifill")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create %s: %v", dir, err)
+	}
+	path := filepath.Join(dir, "clockifill.lock")
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if os.IsExist(err) {
+		owner, _ := os.ReadFile(path)
+		return nil, fmt.Errorf("another ClockiFill run holds the lock (%s); wait for it to finish, or if it is no longer running delete %s (or pass --no-lock)",
+			strings.TrimSpace(string(owner)), path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create lock file: %v", err)
+	}
+	fmt.Fprintf(file, "pid %d, started %s\n", os.Getpid(), time.Now().Format("2006-01-02 15:04:05"))
+	file.Close()
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupted:
+			os.Remove(path)
+			if stopLog != nil {
+				stopLog()
+			}
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	release := func() {
+		signal.Stop(interrupted)
+		close(done)
+		os.Remove(path)
+		releaseHeldLock = nil
+	}
+	releaseHeldLock = release
+	return release, nil
+}
+
+// releaseHeldLock releases the lock while it is held, for exits that skip
+// deferred calls.
+var releaseHeldLock func()
+
+// exit exits with the given code, releasing the lock first if it is held
+// and finishing the log file if there is one.
+func exit(code int) {
+	if releaseHeldLock != nil {
+		releaseHeldLock()
+	}
+	if stopLog != nil {
+		stopLog()
+	}
+	os.Exit(code)
+}
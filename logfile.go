@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// startLog copies everything written to standard output into the log file
+// at path as well, after a header marking the start of the run. A file that
+// has grown past maxSize bytes is first moved to path.1, replacing the one
+// before it. The returned function stops copying and closes the file.
+func startLog(path string, maxSize int64) (func(), error) {
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("error rotating log file: %v", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening log file: %v", err)
+	}
+	fmt.Fprintf(file, "\n=== %s clockifill %s ===\n", time.Now().Format(time.RFC3339), strings.Join(os.Args[1:], " "))
+
+	original := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	os.Stdout = writer
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(io.MultiWriter(original, file), reader)
+		close(copied)
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			writer.Close()
+			<-copied
+			os.Stdout = original
+			file.Close()
+		})
+	}
+	stopLog = stop
+	return stop, nil
+}
+
+// stopLog finishes the log file while one is being written, for exits that
+// skip deferred calls.
+var stopLog func()
@@ -1,386 +1,428 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"bufio"
+	"flag"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"clockifill/clockify"
 	"github.com/joho/godotenv"
 )
 
-const baseURL = "https://api.clockify.me/api/v1"
+const usage = `Usage: clockifill [command] [flags]
+
+Commands:
+  setup         Set up the API key, workspace and defaults step by step
+  fill          Fill working days with time entries (default)
+  delete        Delete entries created by ClockiFill
+  report        Print a CSV report of logged hours
+  doctor        Check the configuration and the connection to Clockify
+  inspect       Print the raw Clockify JSON for a day's entries
+  recent        List the most recent entries
+  tags          List the workspace's tag IDs and names
+  config-init   Write a commented config file from your answers
+  check-config  Check the config file's projects, tasks and hours
+  merge         Merge ClockiFill entries split into adjacent fragments
+  probe-limits  Measure the request rate Clockify allows before rate limiting
+
+Run "clockifill <command> -h" for the flags of a command. Running
+clockifill without a command or flags fills interactively, or runs setup
+if there is no API key or config file yet.
+`
 
-type ClockifyAPI struct {
-	apiKey      string
-	workspaceID string
-	userID      string
-	client      *http.Client
-}
-
-type Workspace struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-}
-
-type Project struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+func main() {
+	command, args := "fill", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	// A bare first run gets the setup wizard instead of terse prompts
+	if len(os.Args) == 1 && isFirstRun() {
+		fmt.Println("No API key or config file found, so let's set ClockiFill up first.")
+		command = "setup"
+	}
+
+	switch command {
+	case "setup":
+		runSetup(args)
+	case "fill":
+		runFill(args)
+	case "delete":
+		runDelete(args)
+	case "report":
+		runReport(args)
+	case "doctor":
+		runDoctor(args)
+	case "inspect":
+		runInspect(args)
+	case "recent":
+		runRecent(args)
+	case "tags":
+		runTags(args)
+	case "config-init":
+		runConfigInit(args)
+	case "check-config":
+		if err := runCheckConfig(args); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			exit(1)
+		}
+	case "merge":
+		runMerge(args)
+	case "probe-limits":
+		runProbeLimits(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Printf("Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
 }
 
-type Task struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+// apiFlags are the connection flags shared by all commands.
+type apiFlags struct {
+	envFiles     *stringList
+	verbose      *bool
+	readTimeout  *time.Duration
+	writeTimeout *time.Duration
+	maxRetries   *int
+	strictClock  *bool
 }
 
-type TimeEntry struct {
-	Start       string `json:"start"`
-	End         string `json:"end"`
-	Description string `json:"description"`
-	ProjectID   string `json:"projectId"`
-	TaskID      string `json:"taskId,omitempty"`
-	Billable    string `json:"billable"`
+func addAPIFlags(fs *flag.FlagSet) *apiFlags {
+	f := &apiFlags{
+		envFiles:     &stringList{},
+		verbose:      fs.Bool("verbose", false, "report which .env file was loaded"),
+		readTimeout:  fs.Duration("read-timeout", 10*time.Second, "timeout for requests that read from Clockify"),
+		writeTimeout: fs.Duration("write-timeout", 30*time.Second, "timeout for requests that create or change entries"),
+		maxRetries:   fs.Int("max-retries", 3, "how many times to retry a request after a rate limit, server error or network error"),
+		strictClock:  fs.Bool("strict-clock", false, "stop instead of warning when the local clock and Clockify's differ by more than 2 minutes"),
+	}
+	fs.Var(f.envFiles, "env-file", "`.env` file to load; repeat to load several (default: the first found of ./.env, .env next to the binary, the user config dir's clockifill/.env and ~/.clockifill.env)")
+	return f
 }
 
-func NewClockifyAPI() (*ClockifyAPI, error) {
-	if err := godotenv.Load(); err != nil {
-		return nil, fmt.Errorf("error loading .env file: %v", err)
-	}
-
-	apiKey := os.Getenv("CLOCKIFY_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("CLOCKIFY_API_KEY not found in environment variables")
-	}
-
-	api := &ClockifyAPI{
-		apiKey: apiKey,
-		client: &http.Client{},
-	}
-
-	var err error
-	if api.workspaceID, err = api.getWorkspaceID(); err != nil {
-		return nil, err
-	}
-
-	if api.userID, err = api.getUserID(); err != nil {
-		return nil, err
+// loadEnv loads the --env-file files, or else the first .env file found in
+// the fallback locations. Variables already set in the environment win. It
+// returns the files loaded.
+func (f *apiFlags) loadEnv() ([]string, error) {
+	if len(*f.envFiles) > 0 {
+		if err := godotenv.Load(*f.envFiles...); err != nil {
+			return nil, fmt.Errorf("error loading .env file: %v", err)
+		}
+		return *f.envFiles, nil
 	}
 
-	return api, nil
-}
-
-func (api *ClockifyAPI) makeRequest(method, endpoint string, body interface{}) (*http.Response, error) {
-	var bodyReader io.Reader
-	if body != nil {
-		jsonData, err := json.Marshal(body)
-		if err != nil {
-			return nil, err
+	candidates := envFileLocations()
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
 		}
-		bodyReader = bytes.NewBuffer(jsonData)
+		if err := godotenv.Load(path); err != nil {
+			return nil, fmt.Errorf("error loading .env file: %v", err)
+		}
+		return []string{path}, nil
 	}
 
-	req, err := http.NewRequest(method, baseURL+endpoint, bodyReader)
-	if err != nil {
-		return nil, err
+	// Without a file the key can still come from the environment
+	if os.Getenv("CLOCKIFY_API_KEY") != "" {
+		return nil, nil
 	}
-
-	req.Header.Set("X-Api-Key", api.apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	return api.client.Do(req)
+	return nil, fmt.Errorf("no .env file found (looked for %s)", strings.Join(candidates, ", "))
 }
 
-func (api *ClockifyAPI) getWorkspaceID() (string, error) {
-	resp, err := api.makeRequest("GET", "/workspaces", nil)
-	if err != nil {
-		return "", err
+// envFileLocations returns where a .env file is looked for, in order.
+func envFileLocations() []string {
+	locations := []string{".env"}
+	if executable, err := os.Executable(); err == nil {
+		locations = append(locations, filepath.Join(filepath.Dir(executable), ".env"))
 	}
-	defer resp.Body.Close()
-
-	var workspaces []Workspace
-	if err := json.NewDecoder(resp.Body).Decode(&workspaces); err != nil {
-		return "", err
+	if configDir, err := os.UserConfigDir(); err == nil {
+		locations = append(locations, filepath.Join(configDir, "clockifill", ".env"))
 	}
-
-	if len(workspaces) == 0 {
-		return "", fmt.Errorf("no workspaces found")
+	if home, err := os.UserHomeDir(); err == nil {
+		locations = append(locations, filepath.Join(home, ".clockifill.env"))
 	}
-
-	return workspaces[0].ID, nil
+	return locations
 }
 
-func (api *ClockifyAPI) getUserID() (string, error) {
-	resp, err := api.makeRequest("GET", "/user", nil)
+func (f *apiFlags) connect() (*clockify.API, error) {
+	loaded, err := f.loadEnv()
 	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var user struct {
-		ID string `json:"id"`
+		return nil, err
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return "", err
+	if *f.verbose {
+		if len(loaded) == 0 {
+			fmt.Println("No .env file loaded; using the environment")
+		} else {
+			fmt.Printf("Loaded environment from %s\n", strings.Join(loaded, ", "))
+		}
 	}
 
-	return user.ID, nil
-}
+	apiKey := os.Getenv("CLOCKIFY_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("CLOCKIFY_API_KEY not found in environment variables")
+	}
 
-func (api *ClockifyAPI) getProjects() ([]Project, error) {
-	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/projects", api.workspaceID), nil)
+	api, err := clockify.NewAPI(clockify.Config{
+		APIKey:       apiKey,
+		WorkspaceID:  os.Getenv("CLOCKIFY_WORKSPACE_ID"),
+		ReadTimeout:  *f.readTimeout,
+		WriteTimeout: *f.writeTimeout,
+		MaxRetries:   *f.maxRetries,
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var projects []Project
-	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
-		return nil, err
+	// A wrong local clock shifts "today" and the default range
+	if err := checkClockSkew(api.ClockSkew()); err != nil {
+		if *f.strictClock {
+			return nil, err
+		}
+		fmt.Printf("Warning: %v\n", err)
 	}
-
-	return projects, nil
+	return api, nil
 }
 
-func (api *ClockifyAPI) getTasks(projectID string) ([]Task, error) {
-	resp, err := api.makeRequest("GET", fmt.Sprintf("/workspaces/%s/projects/%s/tasks", api.workspaceID, projectID), nil)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// maxClockSkew is how far the local clock may be from Clockify's before
+// connecting warns about it.
+const maxClockSkew = 2 * time.Minute
 
-	var tasks []Task
-	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
-		return nil, err
+func checkClockSkew(skew time.Duration) error {
+	switch {
+	case skew > maxClockSkew:
+		return fmt.Errorf("the local clock is %s ahead of Clockify's; check the system time", skew)
+	case skew < -maxClockSkew:
+		return fmt.Errorf("the local clock is %s behind Clockify's; check the system time", -skew)
 	}
-
-	return tasks, nil
+	return nil
 }
 
-func (api *ClockifyAPI) hasTimeEntry(projectID string, startTime, endTime time.Time) (bool, error) {
-	params := fmt.Sprintf("?start=%s&end=%s&project=%s",
-		startTime.UTC().Format(time.RFC3339),
-		endTime.UTC().Format(time.RFC3339),
-		projectID)
+// stringList is a flag that can be given several times.
+type stringList []string
 
-	endpoint := fmt.Sprintf("/workspaces/%s/user/%s/time-entries%s",
-		api.workspaceID, api.userID, params)
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
 
-	resp, err := api.makeRequest("GET", endpoint, nil)
-	if err != nil {
-		return false, err
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// printRetryStats reports any retries the run needed, to show how flaky the
+// API was.
+func printRetryStats(api *clockify.API) {
+	if stats := api.RetryStats(); stats.Retries > 0 {
+		fmt.Println(stats)
 	}
-	defer resp.Body.Close()
+}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
+// captureStdout sends everything written to standard output into a buffer
+// until the returned function is called, which restores standard output and
+// returns what was written.
+func captureStdout() func() []byte {
+	original := os.Stdout
+	reader, writer, err := os.Pipe()
 	if err != nil {
-		return false, fmt.Errorf("error reading response body: %v", err)
+		return func() []byte { return nil }
 	}
+	os.Stdout = writer
 
-	// Handle empty response
-	if len(body) == 0 {
-		return false, nil
-	}
+	captured := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		captured <- data
+	}()
 
-	// Try to decode the response
-	var entries []interface{}
-	if err := json.Unmarshal(body, &entries); err != nil {
-		return false, fmt.Errorf("error decoding response (status %d): %v - body: %s",
-			resp.StatusCode, err, string(body))
+	return func() []byte {
+		writer.Close()
+		os.Stdout = original
+		return <-captured
 	}
-
-	return len(entries) > 0, nil
 }
 
-func (api *ClockifyAPI) addTimeEntry(projectID string, startTime, endTime time.Time, description string, taskID string, billable bool) error {
-	entry := TimeEntry{
-		Start:       startTime.UTC().Format(time.RFC3339),
-		End:         endTime.UTC().Format(time.RFC3339),
-		Description: description,
-		ProjectID:   projectID,
-		Billable:    strconv.FormatBool(billable),
-	}
+// rangeFlags select the days a command works on, shared by all commands.
+type rangeFlags struct {
+	from             *string
+	to               *string
+	lastWeek         *bool
+	weekStart        *string
+	throughYesterday *bool
+	dateLayout       *string
+}
 
-	if taskID != "" {
-		entry.TaskID = taskID
+func addRangeFlags(fs *flag.FlagSet) *rangeFlags {
+	return &rangeFlags{
+		from:             fs.String("from", "", "first day of the range (default: start of the current month)"),
+		to:               fs.String("to", "", "last day of the range (default: today)"),
+		lastWeek:         fs.Bool("last-week", false, "use the previous full week (Monday to Sunday, or from --week-start) instead of the current month"),
+		weekStart:        fs.String("week-start", "monday", "day weeks start on, for --last-week, --weekly-hours, weekly reports and weekly approval periods"),
+		throughYesterday: fs.Bool("through-yesterday", false, "end the range at the end of yesterday so today is never included"),
+		dateLayout:       fs.String("date-layout", "2006-01-02", "Go time layout used to parse dates given in flags"),
 	}
+}
 
-	resp, err := api.makeRequest("POST", fmt.Sprintf("/workspaces/%s/time-entries", api.workspaceID), entry)
-	if err != nil {
-		return err
+// resolve returns the first and last moment of the selected range. The range
+// starts at midnight, so which days it covers doesn't depend on the time of
+// day. Every problem with the flags is returned so they can all be reported
+// at once.
+func (f *rangeFlags) resolve(now time.Time) (time.Time, time.Time, []error) {
+	var problems []error
+	if _, err := parseWeekday(*f.weekStart); err != nil {
+		problems = append(problems, fmt.Errorf("invalid --week-start: %v", err))
+	}
+	rangeStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	rangeEnd := now
+	if *f.throughYesterday {
+		if *f.to != "" {
+			problems = append(problems, fmt.Errorf("--through-yesterday cannot be combined with --to"))
+		}
+		rangeEnd = time.Date(now.Year(), now.Month(), now.Day()-1, 23, 59, 59, 0, now.Location())
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated {
-		return fmt.Errorf("failed to create time entry: %s", resp.Status)
+	if *f.lastWeek {
+		if *f.from != "" || *f.to != "" || *f.throughYesterday {
+			problems = append(problems, fmt.Errorf("--last-week cannot be combined with --from, --to or --through-yesterday"))
+		}
+		rangeStart, rangeEnd = clockify.PreviousWeek(now, f.firstWeekday())
 	}
-
-	return nil
-}
-
-func getWorkingDays(startDate, endDate time.Time) []time.Time {
-	var workingDays []time.Time
-	currentDate := startDate
-
-	for currentDate.Before(endDate) || currentDate.Equal(endDate) {
-		if currentDate.Weekday() != time.Saturday && currentDate.Weekday() != time.Sunday {
-			workingDays = append(workingDays, currentDate)
+	if *f.from != "" {
+		if from, err := parseDate(*f.from, *f.dateLayout); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --from: %v", err))
+		} else {
+			rangeStart = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, now.Location())
 		}
-		currentDate = currentDate.AddDate(0, 0, 1)
 	}
-
-	return workingDays
-}
-
-func getDescriptionMode() int {
-	fmt.Println("\nHow would you like to handle task descriptions?")
-	fmt.Println("1. Use default description ('Standard workday') for all entries")
-	fmt.Println("2. Set one custom description for all entries")
-	fmt.Println("3. Enter custom description for each day")
-
-	var choice int
-	for {
-		fmt.Print("\nEnter your choice (1-3): ")
-		fmt.Scanln(&choice)
-		if choice >= 1 && choice <= 3 {
-			return choice
+	if *f.to != "" {
+		if to, err := parseDate(*f.to, *f.dateLayout); err != nil {
+			problems = append(problems, fmt.Errorf("invalid --to: %v", err))
+		} else {
+			rangeEnd = time.Date(to.Year(), to.Month(), to.Day(), 23, 59, 59, 0, now.Location())
 		}
-		fmt.Println("Please enter a valid choice (1-3)")
 	}
+	// On the 1st, the month up to yesterday has no days; that leaves nothing
+	// to do rather than being a mistake
+	emptyMonth := *f.throughYesterday && *f.from == "" && !*f.lastWeek
+	if rangeStart.After(rangeEnd) && !emptyMonth {
+		problems = append(problems, fmt.Errorf("invalid date range: %s is after %s", rangeStart.Format("2006-01-02"), rangeEnd.Format("2006-01-02")))
+	}
+	return rangeStart, rangeEnd, problems
 }
 
-func getBillablePreference() bool {
-	fmt.Print("\nMake entries billable? (y/N): ")
-	var input string
-	fmt.Scanln(&input)
-	input = strings.ToLower(input)
-	return input == "y" || input == "yes"
-}
-
-func main() {
-	api, err := NewClockifyAPI()
-	if err != nil {
-		fmt.Printf("Error initializing Clockify API: %v\n", err)
-		return
+// emptyRange reports whether a resolved range has no days, as with
+// --through-yesterday on the 1st of the month, saying so if it hasn't.
+func emptyRange(rangeStart, rangeEnd time.Time) bool {
+	if !rangeStart.After(rangeEnd) {
+		return false
 	}
+	fmt.Println("Nothing to do: the range ends yesterday, before the start of the month")
+	return true
+}
 
-	// Get projects
-	projects, err := api.getProjects()
+// firstWeekday returns the day weeks start on, Monday if --week-start is
+// invalid (resolve reports that).
+func (f *rangeFlags) firstWeekday() time.Weekday {
+	weekday, err := parseWeekday(*f.weekStart)
 	if err != nil {
-		fmt.Printf("Error getting projects: %v\n", err)
-		return
-	}
-
-	fmt.Println("\nAvailable Projects:")
-	for i, project := range projects {
-		fmt.Printf("%d. %s\n", i+1, project.Name)
+		return time.Monday
 	}
+	return weekday
+}
 
-	// Select project
-	var projectIdx int
-	for {
-		fmt.Print("\nSelect project number: ")
-		fmt.Scanln(&projectIdx)
-		projectIdx--
-		if projectIdx >= 0 && projectIdx < len(projects) {
-			break
+// parseWeekday parses an English day name such as "sunday" or "Sun".
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(value, day.String()) || strings.EqualFold(value, day.String()[:3]) {
+			return day, nil
 		}
-		fmt.Printf("Please enter a number between 1 and %d\n", len(projects))
 	}
+	return 0, fmt.Errorf("unknown day %q: expected a day name such as monday or sunday", value)
+}
 
-	selectedProject := projects[projectIdx]
-
-	// Get tasks
-	tasks, err := api.getTasks(selectedProject.ID)
+// parseDate parses a date flag value using the given Go time layout.
+func parseDate(value, layout string) (time.Time, error) {
+	date, err := time.ParseInLocation(layout, value, time.Local)
 	if err != nil {
-		fmt.Printf("Error getting tasks: %v\n", err)
-		return
+		example := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.Local).Format(layout)
+		return time.Time{}, fmt.Errorf("cannot parse %q: expected layout %q (e.g. %s)", value, layout, example)
 	}
+	return date, nil
+}
 
-	var selectedTask *Task
-	if len(tasks) > 0 {
-		fmt.Println("\nAvailable Tasks:")
-		for i, task := range tasks {
-			fmt.Printf("%d. %s\n", i+1, task.Name)
-		}
-
-		fmt.Print("\nPress Enter to skip task selection or enter a task number: ")
-		var taskInput string
-		fmt.Scanln(&taskInput)
-
-		if taskInput != "" {
-			taskIdx, err := strconv.Atoi(taskInput)
-			if err == nil && taskIdx > 0 && taskIdx <= len(tasks) {
-				selectedTask = &tasks[taskIdx-1]
-			} else {
-				fmt.Println("Invalid task number, proceeding without task selection")
-			}
+// splitList splits a comma-separated flag value, trimming whitespace and
+// dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-	} else {
-		fmt.Println("\nNo tasks found for this project, proceeding without task selection")
-	}
-
-	descriptionMode := getDescriptionMode()
-	billable := getBillablePreference()
-
-	defaultDescription := "Standard workday"
-	if descriptionMode == 2 {
-		fmt.Print("\nEnter the description to use for all entries: ")
-		fmt.Scanln(&defaultDescription)
 	}
+	return items
+}
 
-	// Calculate date range
-	now := time.Now()
-	startOfMonth := time.Date(now.Year(), now.Month(), 1, 9, 0, 0, 0, now.Location())
-	workingDays := getWorkingDays(startOfMonth, now)
-
-	skippedCount := 0
-	addedCount := 0
+// useColor is whether names are tinted with their Clockify colors: only on a
+// terminal, and not when NO_COLOR is set (https://no-color.org).
+var useColor = os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
 
-	for _, day := range workingDays {
-		startTime := time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, day.Location())
-		endTime := time.Date(day.Year(), day.Month(), day.Day(), 16, 30, 0, 0, day.Location())
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-		hasEntry, err := api.hasTimeEntry(selectedProject.ID, startTime, endTime)
-		if err != nil {
-			fmt.Printf("Error checking time entry for %s: %v\n", day.Format("2006-01-02"), err)
-			continue
-		}
+// tint colors text with a Clockify color when colors are enabled.
+func tint(text, color string) string {
+	if !useColor {
+		return text
+	}
+	return clockify.Tint(text, color)
+}
 
-		if hasEntry {
-			fmt.Printf("Skipping %s - Time entry already exists\n", day.Format("2006-01-02"))
-			skippedCount++
-			continue
-		}
+// stdin is shared by all prompts so no input is lost in another reader's
+// buffer.
+var stdin = bufio.NewReader(os.Stdin)
 
-		description := defaultDescription
-		if descriptionMode == 3 {
-			fmt.Printf("\nEnter description for %s: ", day.Format("2006-01-02"))
-			fmt.Scanln(&description)
-		}
+// readLine reads a whole line of input, spaces included, without the
+// surrounding whitespace. At the end of the input it returns an empty line,
+// so questions with a default take it.
+func readLine() string {
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		stdinClosed = true
+	}
+	return strings.TrimSpace(line)
+}
 
-		taskID := ""
-		if selectedTask != nil {
-			taskID = selectedTask.ID
-		}
+var stdinClosed bool
 
-		if err := api.addTimeEntry(selectedProject.ID, startTime, endTime, description, taskID, billable); err != nil {
-			if strings.Contains(err.Error(), "EOF") {
-				fmt.Printf("Skipping %s - Unable to verify existing entries\n", day.Format("2006-01-02"))
-			} else {
-				fmt.Printf("Failed to add time entry for %s: %v\n", day.Format("2006-01-02"), err)
-			}
-			continue
-		}
+// exitIfNoInput stops a prompt that has no default from asking forever once
+// the input has ended.
+func exitIfNoInput() {
+	if stdinClosed {
+		fmt.Println()
+		fmt.Println("No more input, exiting")
+		exit(1)
+	}
+}
 
-		fmt.Printf("Added time entry for %s\n", day.Format("2006-01-02"))
-		addedCount++
+// promptDefault asks for a value, showing the default in brackets; pressing
+// Enter accepts it.
+func promptDefault(question, defaultValue string) string {
+	fmt.Printf("%s [%s]: ", question, defaultValue)
+	if input := readLine(); input != "" {
+		return input
 	}
+	return defaultValue
+}
 
-	fmt.Printf("\nSummary: Added %d entries, Skipped %d existing entries\n", addedCount, skippedCount)
+// confirm asks a yes/no question, defaulting to no.
+func confirm(question string) bool {
+	fmt.Printf("%s (y/N): ", question)
+	input := strings.ToLower(readLine())
+	return input == "y" || input == "yes"
 }
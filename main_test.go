@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"testing"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// resolveRange parses args as range flags and resolves them at now.
+func resolveRange(t *testing.T, now time.Time, args ...string) (time.Time, time.Time, []error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	rangeOpts := addRangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return rangeOpts.resolve(now)
+}
+
+func TestFirstOfMonthKeptWhateverTheTime(t *testing.T) {
+	want := []string{"2024-07-01"}
+	for _, hour := range []int{8, 10} {
+		now := time.Date(2024, 7, 1, hour, 0, 0, 0, time.UTC)
+		start, end, problems := resolveRange(t, now)
+		if len(problems) > 0 {
+			t.Fatalf("at %02d:00: %v", hour, problems)
+		}
+
+		var got []string
+		for _, day := range clockify.GetWorkingDays(start, end) {
+			got = append(got, day.Format("2006-01-02"))
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("at %02d:00 the working days are %v, want %v", hour, got, want)
+		}
+	}
+}
+
+func TestThroughYesterdayOnTheFirstIsEmpty(t *testing.T) {
+	now := time.Date(2024, 7, 1, 15, 0, 0, 0, time.UTC)
+	start, end, problems := resolveRange(t, now, "--through-yesterday")
+	if len(problems) > 0 {
+		t.Fatalf("got problems %v, want none", problems)
+	}
+	if !emptyRange(start, end) {
+		t.Errorf("range %s to %s isn't empty", start, end)
+	}
+	if days := clockify.GetWorkingDays(start, end); len(days) != 0 {
+		t.Errorf("got working days %v, want none", days)
+	}
+
+	// The next day, the 1st itself is in range
+	start, end, _ = resolveRange(t, now.AddDate(0, 0, 1), "--through-yesterday")
+	if emptyRange(start, end) || len(clockify.GetWorkingDays(start, end)) != 1 {
+		t.Errorf("on the 2nd, got range %s to %s, want the 1st", start, end)
+	}
+}
+
+func TestToIncludesTheWholeLastDay(t *testing.T) {
+	now := time.Date(2024, 6, 20, 15, 0, 0, 0, time.UTC)
+	start, end, problems := resolveRange(t, now, "--from", "2024-06-10", "--to", "2024-06-14")
+	if len(problems) > 0 {
+		t.Fatalf("got problems %v", problems)
+	}
+	if got := end.Format("2006-01-02 15:04:05"); got != "2024-06-14 23:59:59" {
+		t.Errorf("range ends %s, want 2024-06-14 23:59:59", got)
+	}
+
+	var got []string
+	for _, day := range clockify.GetWorkingDays(start, end) {
+		got = append(got, day.Format("2006-01-02"))
+	}
+	if want := []string{"2024-06-10", "2024-06-11", "2024-06-12", "2024-06-13", "2024-06-14"}; !slices.Equal(got, want) {
+		t.Errorf("got working days %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// runMerge merges ClockiFill's entries that are split into adjacent
+// fragments on the same day back into single entries.
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	markerTag := fs.String("marker-tag", "clockifill", "tag identifying the entries created by ClockiFill; only these are merged")
+	maxGap := fs.Duration("max-gap", time.Minute, "merge entries separated by less than this, e.g. 1h to close a lunch break")
+	yes := fs.Bool("yes", false, "merge without asking for confirmation")
+	noLock := fs.Bool("no-lock", false, "don't take the lock that stops two runs from changing entries at the same time")
+	rangeOpts := addRangeFlags(fs)
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	rangeStart, rangeEnd, problems := rangeOpts.resolve(time.Now())
+	if *markerTag == "" {
+		problems = append(problems, fmt.Errorf("--marker-tag is required to tell ClockiFill's entries apart from manual ones"))
+	}
+	if *maxGap <= 0 {
+		problems = append(problems, fmt.Errorf("--max-gap must be positive"))
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Invalid options:\n%v\n", errors.Join(problems...))
+		return
+	}
+	if emptyRange(rangeStart, rangeEnd) {
+		return
+	}
+
+	if !*noLock {
+		release, err := acquireLock()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer release()
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	markerTagID, err := api.FindTag(*markerTag)
+	if err != nil {
+		fmt.Printf("Error looking up marker tag %q: %v\n", *markerTag, err)
+		return
+	}
+	if markerTagID == "" {
+		fmt.Printf("Nothing to merge: the %q tag doesn't exist\n", *markerTag)
+		return
+	}
+
+	entries, err := api.GetTimeEntries("", rangeStart, rangeEnd)
+	if err != nil {
+		fmt.Printf("Error getting time entries: %v\n", err)
+		return
+	}
+
+	groups := clockify.FindAdjacent(entries, markerTagID, *maxGap)
+	if len(groups) == 0 {
+		fmt.Println("No adjacent ClockiFill entries found in the range")
+		return
+	}
+
+	projects, err := api.GetProjects()
+	if err != nil {
+		fmt.Printf("Error getting projects: %v\n", err)
+		return
+	}
+	projectNames := make(map[string]string)
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	for _, group := range groups {
+		start, end := clockify.MergedSpan(group)
+		fmt.Printf("%s %s: %d entries -> %s-%s %q\n", start.Local().Format("2006-01-02"), projectNames[group[0].ProjectID],
+			len(group), start.Local().Format("15:04"), end.Local().Format("15:04"), group[0].Description)
+	}
+	if !*yes && !confirm(fmt.Sprintf("\nMerge these %d groups?", len(groups))) {
+		fmt.Println("Nothing merged")
+		return
+	}
+
+	merged, failed := 0, 0
+	for _, group := range groups {
+		day := group[0].TimeInterval.Start.Local().Format("2006-01-02")
+		if err := clockify.MergeEntries(api, group); err != nil {
+			fmt.Printf("Failed to merge entries for %s: %v\n", day, err)
+			failed++
+			continue
+		}
+		fmt.Printf("Merged %d entries for %s\n", len(group), day)
+		merged++
+	}
+
+	fmt.Printf("\nSummary: Merged %d groups, Failed %d\n", merged, failed)
+	printRetryStats(api)
+}
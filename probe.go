@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+// runProbeLimits measures how fast the workspace answers before rate
+// limiting, to help choose --read-concurrency and --write-concurrency on a
+// shared API key. It only reads.
+func runProbeLimits(args []string) {
+	fs := flag.NewFlagSet("probe-limits", flag.ExitOnError)
+	requests := fs.Int("requests", 50, "most read requests to send")
+	concurrency := fs.Int("concurrency", 10, "how many requests to have in flight at once")
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	if *requests < 1 || *concurrency < 1 {
+		fmt.Println("Invalid options: --requests and --concurrency must be at least 1")
+		return
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Sending up to %d read requests, %d at a time...\n", *requests, *concurrency)
+	probe, err := api.ProbeRateLimit(*requests, *concurrency)
+	if err != nil {
+		fmt.Printf("Error probing: %v\n", err)
+		return
+	}
+
+	if probe.Limited {
+		fmt.Printf("Rate limited after %d requests in %.1fs: about %.1f requests/second", probe.Succeeded, probe.Elapsed.Seconds(), probe.PerSecond())
+		if probe.RetryAfter != "" {
+			fmt.Printf(" (Retry-After: %ss)", probe.RetryAfter)
+		}
+		fmt.Println()
+	} else {
+		fmt.Printf("Not rate limited: %d requests in %.1fs, %.1f requests/second; the limit is higher (try more --requests or --concurrency)\n",
+			probe.Succeeded, probe.Elapsed.Seconds(), probe.PerSecond())
+	}
+
+	names := make([]string, 0, len(probe.Headers))
+	for name := range probe.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, probe.Headers[name])
+	}
+}
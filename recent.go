@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"clockifill/clockify"
+)
+
+// runRecent prints the most recent entries as a table, for checking a fill
+// without opening the Clockify web UI.
+func runRecent(args []string) {
+	fs := flag.NewFlagSet("recent", flag.ExitOnError)
+	apiOpts := addAPIFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: clockifill recent [flags] [count]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	limit := 10
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if fs.NArg() == 1 {
+		n, err := strconv.Atoi(fs.Arg(0))
+		if err != nil || n < 1 || n > 1000 {
+			fmt.Printf("Invalid count %q: expected a number from 1 to 1000\n", fs.Arg(0))
+			return
+		}
+		limit = n
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	projects, err := api.GetProjects()
+	if err != nil {
+		fmt.Printf("Error getting projects: %v\n", err)
+		return
+	}
+	projectNames := make(map[string]string)
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	entries, err := api.GetRecentEntries(limit)
+	if err != nil {
+		fmt.Printf("Error getting time entries: %v\n", err)
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("No time entries found")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "DATE\tDURATION\tPROJECT\tDESCRIPTION")
+	for _, entry := range entries {
+		duration := "running"
+		if entry.TimeInterval.End != nil {
+			duration = clockify.FormatDuration(entry.TimeInterval.End.Sub(entry.TimeInterval.Start))
+		}
+		project := projectNames[entry.ProjectID]
+		if project == "" {
+			project = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n",
+			entry.TimeInterval.Start.Local().Format("2006-01-02 15:04"), duration, project, entry.Description)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"clockifill/clockify"
+)
+
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	granularity := fs.String("report-granularity", "day", "one row per day and project, or per week and project with the number of days: day or week")
+	rangeOpts := addRangeFlags(fs)
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	rangeStart, rangeEnd, problems := rangeOpts.resolve(time.Now())
+	if *granularity != "day" && *granularity != "week" {
+		problems = append(problems, fmt.Errorf("invalid --report-granularity %q: expected day or week", *granularity))
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Invalid options:\n%v\n", errors.Join(problems...))
+		return
+	}
+	if emptyRange(rangeStart, rangeEnd) {
+		return
+	}
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	projects, err := api.GetProjects()
+	if err != nil {
+		fmt.Printf("Error getting projects: %v\n", err)
+		return
+	}
+	projectNames := make(map[string]string)
+	for _, project := range projects {
+		projectNames[project.ID] = project.Name
+	}
+
+	entries, err := api.GetTimeEntries("", rangeStart, rangeEnd)
+	if err != nil {
+		fmt.Printf("Error getting time entries: %v\n", err)
+		return
+	}
+
+	// Total the hours logged per day or week and project. Weeks are
+	// labelled with their first day and count the days with time logged
+	type reportKey struct {
+		date    string
+		project string
+	}
+	totals := make(map[reportKey]time.Duration)
+	days := make(map[reportKey]map[string]bool)
+	for _, entry := range entries {
+		if entry.TimeInterval.End == nil {
+			continue
+		}
+		start := entry.TimeInterval.Start.Local()
+		key := reportKey{
+			date:    start.Format("2006-01-02"),
+			project: projectNames[entry.ProjectID],
+		}
+		if *granularity == "week" {
+			key.date = clockify.WeekStart(start, rangeOpts.firstWeekday()).Format("2006-01-02")
+		}
+		totals[key] += entry.TimeInterval.End.Sub(entry.TimeInterval.Start)
+		if days[key] == nil {
+			days[key] = make(map[string]bool)
+		}
+		days[key][start.Format("2006-01-02")] = true
+	}
+
+	keys := make([]reportKey, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].date != keys[j].date {
+			return keys[i].date < keys[j].date
+		}
+		return keys[i].project < keys[j].project
+	})
+
+	writer := csv.NewWriter(os.Stdout)
+	if *granularity == "week" {
+		writer.Write([]string{"week", "project", "hours", "days"})
+	} else {
+		writer.Write([]string{"date", "project", "hours"})
+	}
+	for _, key := range keys {
+		row := []string{key.date, key.project, strconv.FormatFloat(totals[key].Hours(), 'f', 2, 64)}
+		if *granularity == "week" {
+			row = append(row, strconv.Itoa(len(days[key])))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+	"github.com/joho/godotenv"
+)
+
+// runSetup walks a new user through connecting to Clockify and choosing a
+// default project, hours and billable setting, then saves the answers to a
+// .env file and the config file. It creates nothing in Clockify.
+func runSetup(args []string) {
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	configPath := fs.String("config", "", "config file to write (default: ~/.clockifill.yaml)")
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	fmt.Println("Welcome to ClockiFill! A few questions set it up; nothing is created in Clockify.")
+
+	// The key comes from the environment or .env if there is one
+	fmt.Println("\nStep 1: API key")
+	apiOpts.loadEnv()
+	apiKey := os.Getenv("CLOCKIFY_API_KEY")
+	newKey := apiKey == ""
+	var api *clockify.API
+	for api == nil {
+		if apiKey == "" {
+			fmt.Println("Find it in Clockify under your profile picture > Preferences > Advanced, then paste it here.")
+			fmt.Print("API key: ")
+			if apiKey = readLine(); apiKey == "" {
+				exitIfNoInput()
+				continue
+			}
+		}
+		var err error
+		api, err = clockify.NewAPI(clockify.Config{
+			APIKey:       apiKey,
+			ReadTimeout:  *apiOpts.readTimeout,
+			WriteTimeout: *apiOpts.writeTimeout,
+			MaxRetries:   *apiOpts.maxRetries,
+		})
+		if err != nil {
+			fmt.Printf("That key didn't work: %v\n", err)
+			exitIfNoInput()
+			apiKey, newKey = "", true
+		}
+	}
+	fmt.Printf("The key %s works\n", redact(apiKey))
+
+	fmt.Println("\nStep 2: Workspace")
+	workspaces, err := api.GetWorkspaces()
+	if err != nil {
+		fmt.Printf("Error getting workspaces: %v\n", err)
+		return
+	}
+	workspace := workspaces[0]
+	for _, candidate := range workspaces {
+		if candidate.ID == os.Getenv("CLOCKIFY_WORKSPACE_ID") {
+			workspace = candidate
+		}
+	}
+	if len(workspaces) > 1 {
+		for i, candidate := range workspaces {
+			fmt.Printf("%d. %s\n", i+1, candidate.Name)
+		}
+		for {
+			input := promptDefault("Workspace number", workspace.Name)
+			if input == workspace.Name {
+				break
+			}
+			if idx, err := strconv.Atoi(input); err == nil && idx > 0 && idx <= len(workspaces) {
+				workspace = workspaces[idx-1]
+				break
+			}
+			exitIfNoInput()
+			fmt.Printf("Please enter a number between 1 and %d\n", len(workspaces))
+		}
+	}
+	fmt.Printf("Using workspace %s\n", workspace.Name)
+	changedWorkspace := workspace.ID != os.Getenv("CLOCKIFY_WORKSPACE_ID") && len(workspaces) > 1
+
+	if newKey || changedWorkspace {
+		envPath, err := setupEnvPath()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if confirm(fmt.Sprintf("Save the key and workspace to %s?", envPath)) {
+			if err := saveEnv(envPath, apiKey, workspace.ID); err != nil {
+				fmt.Printf("Error saving %s: %v\n", envPath, err)
+				return
+			}
+			fmt.Printf("Saved %s\n", envPath)
+		}
+	}
+
+	if api, err = clockify.NewAPI(clockify.Config{
+		APIKey:       apiKey,
+		WorkspaceID:  workspace.ID,
+		ReadTimeout:  *apiOpts.readTimeout,
+		WriteTimeout: *apiOpts.writeTimeout,
+		MaxRetries:   *apiOpts.maxRetries,
+	}); err != nil {
+		fmt.Printf("Error connecting to workspace %s: %v\n", workspace.Name, err)
+		return
+	}
+
+	fmt.Println("\nStep 3: Default project")
+	projects, err := api.GetProjects()
+	if err != nil {
+		fmt.Printf("Error getting projects: %v\n", err)
+		return
+	}
+	var project *clockify.Project
+	if len(projects) == 0 {
+		fmt.Println("The workspace has no projects yet; create one in Clockify first")
+		return
+	}
+	for i, label := range clockify.ProjectLabels(projects) {
+		fmt.Printf("%d. %s\n", i+1, tint(label, projects[i].Color))
+	}
+	for project == nil {
+		fmt.Print("Project you usually fill (Enter to choose each time): ")
+		input := readLine()
+		if input == "" {
+			break
+		}
+		if idx, err := strconv.Atoi(input); err == nil && idx > 0 && idx <= len(projects) {
+			project = &projects[idx-1]
+			break
+		}
+		fmt.Printf("Please enter a number between 1 and %d\n", len(projects))
+	}
+
+	// Hours and billable are kept with the default project
+	var start, end, billable string
+	if project != nil {
+		fmt.Println("\nStep 4: Typical hours")
+		start = promptClock("Start time, e.g. 09:00 or 9am (Enter for 09:00): ")
+		end = promptClock("End time, e.g. 16:30 or 4:30pm (Enter for 16:30): ")
+		startOffset, endOffset, _ := projectConfig{Start: start, End: end}.hours()
+		if endOffset <= startOffset {
+			fmt.Println("The end time isn't after the start time; keeping 09:00 to 16:30")
+			start, end = "", ""
+		}
+
+		fmt.Println("\nStep 5: Billable")
+		fmt.Print("Are your entries billable? (y/n, Enter to be asked each time): ")
+		switch strings.ToLower(readLine()) {
+		case "y", "yes":
+			billable = "true"
+		case "n", "no":
+			billable = "false"
+		}
+	}
+
+	path := *configPath
+	if path == "" {
+		if path = defaultConfigPath(); path == "" {
+			fmt.Println("Error: cannot find your home directory; pass --config")
+			return
+		}
+	}
+	if project == nil {
+		fmt.Println("\nNo default project chosen, so there is nothing to save to a config file.")
+	} else {
+		var text strings.Builder
+		fmt.Fprintf(&text, "# ClockiFill config file, written by \"clockifill setup\" on %s.\n", time.Now().Format("2006-01-02"))
+		text.WriteString("# See the \"Config file\" section of the README for all settings.\n\n")
+		text.WriteString("# Project filled when no --project is given\n")
+		fmt.Fprintf(&text, "default_project: %s\n\n", yamlString(project.Name))
+		text.WriteString("projects:\n")
+		fmt.Fprintf(&text, "  %s:\n", yamlString(project.Name))
+		if billable != "" {
+			fmt.Fprintf(&text, "    billable: %s\n", billable)
+		}
+		if start != "" {
+			fmt.Fprintf(&text, "    start: %s\n", yamlString(start))
+		}
+		if end != "" {
+			fmt.Fprintf(&text, "    end: %s\n", yamlString(end))
+		}
+
+		fmt.Printf("\n%s\n", text.String())
+		question := fmt.Sprintf("Save this to %s?", path)
+		if _, err := os.Stat(path); err == nil {
+			question = fmt.Sprintf("Replace %s with this? Its current settings are lost", path)
+		}
+		if confirm(question) {
+			if err := os.WriteFile(path, []byte(text.String()), 0o644); err != nil {
+				fmt.Printf("Error writing config file: %v\n", err)
+				return
+			}
+			fmt.Printf("Saved %s\n", path)
+		}
+	}
+
+	fmt.Println("\nAll set. Run clockifill to fill your timesheet, or clockifill --dry-run to see what it would do first.")
+}
+
+// setupEnvPath returns the .env file setup saves the key to, in the user
+// config directory where .env files are looked for.
+func setupEnvPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find the config directory: %v", err)
+	}
+	return filepath.Join(configDir, "clockifill", ".env"), nil
+}
+
+// saveEnv sets the key and workspace in the .env file at path, keeping any
+// other variables in it. The file is only readable by the user.
+func saveEnv(path, apiKey, workspaceID string) error {
+	env, err := godotenv.Read(path)
+	if os.IsNotExist(err) {
+		env, err = map[string]string{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	env["CLOCKIFY_API_KEY"] = apiKey
+	env["CLOCKIFY_WORKSPACE_ID"] = workspaceID
+
+	content, err := godotenv.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content+"\n"), 0o600)
+}
+
+// isFirstRun reports whether ClockiFill has never been set up: no API key
+// in the environment or a .env file, and no config file.
+func isFirstRun() bool {
+	if os.Getenv("CLOCKIFY_API_KEY") != "" {
+		return false
+	}
+	for _, path := range envFileLocations() {
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+	if path := defaultConfigPath(); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+	return true
+}
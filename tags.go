@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runTags prints the workspace's tags as "ID<tab>name" lines, for looking
+// up tag IDs in scripts.
+func runTags(args []string) {
+	fs := flag.NewFlagSet("tags", flag.ExitOnError)
+	apiOpts := addAPIFlags(fs)
+	fs.Parse(args)
+
+	api, err := apiOpts.connect()
+	if err != nil {
+		fmt.Printf("Error initializing Clockify API: %v\n", err)
+		return
+	}
+
+	tags, err := api.GetTags()
+	if err != nil {
+		fmt.Printf("Error getting tags: %v\n", err)
+		return
+	}
+	for _, tag := range tags {
+		fmt.Printf("%s\t%s\n", tag.ID, tag.Name)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"clockifill/clockify"
+)
+
+// webhookPayload is the JSON body posted to --webhook-url after a fill.
+type webhookPayload struct {
+	Added   int    `json:"added"`
+	Skipped int    `json:"skipped"`
+	Failed  int    `json:"failed"`
+	Project string `json:"project"`
+	Period  string `json:"period"`
+}
+
+// notifyWebhook posts the outcome of a fill to url, either as the plain
+// payload or, for the slack format, as a Slack message.
+func notifyWebhook(url, format string, payload webhookPayload) error {
+	var body interface{} = payload
+	if format == "slack" {
+		body = struct {
+			Text string `json:"text"`
+		}{fmt.Sprintf("ClockiFill filled %s for %s: added %d, skipped %d, failed %d",
+			payload.Project, payload.Period, payload.Added, payload.Skipped, payload.Failed)}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func newWebhookPayload(summary clockify.Summary, projects []clockify.Project, rangeStart, rangeEnd time.Time) webhookPayload {
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		names[i] = project.Name
+	}
+	return webhookPayload{
+		Added:   summary.Added,
+		Skipped: summary.Skipped,
+		Failed:  summary.Failed,
+		Project: strings.Join(names, ", "),
+		Period:  rangeStart.Format("2006-01-02") + " to " + rangeEnd.Format("2006-01-02"),
+	}
+}